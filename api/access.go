@@ -2,11 +2,30 @@ package api
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/handlers"
+	"github.com/qiuxsgit/go-short-link/ratelimit"
 )
 
-// SetupAccessRoutes 设置访问API路由
-func SetupAccessRoutes(router *gin.Engine, handler *handlers.ShortLinkHandler) {
-	// 注册重定向路由
-	router.GET("/s/:code", handler.RedirectShortLink)
+// SetupAccessRoutes 设置访问API路由，路径前缀为空时短链接位于域名根路径
+func SetupAccessRoutes(router *gin.Engine, handler *handlers.ShortLinkHandler, limiter *ratelimit.Limiter, config *conf.Config) {
+	// 保留路径，优先于短码匹配
+	router.GET("/healthz", handler.HealthCheck)
+	router.GET("/favicon.ico", handler.Favicon)
+	router.GET("/robots.txt", handler.RobotsTxt)
+
+	// 访问者举报短链接，按IP限流
+	reportLimit := RateLimitMiddleware(limiter, "report", ratelimit.RuleFromConfig(config.Server.Access.Report.RateLimitRule()), nil)
+	router.POST("/report/:code", reportLimit, handler.ReportShortLink)
+
+	// 访问域名根路径时跳转到域名的兜底地址
+	router.GET("/", handler.RedirectDomainRoot)
+
+	// 注册重定向路由，二维码（.qr）和预览（+）后缀由处理器解析
+	redirectLimit := RateLimitMiddleware(limiter, "redirect", ratelimit.RuleFromConfig(config.RateLimit.Redirect), handler.RenderRateLimited)
+	shortLinks := router.Group("/"+config.Server.Access.ShortPathPrefix(), redirectLimit)
+	shortLinks.GET("/:code", handler.RedirectShortLink)
+
+	// 前缀模式：短码之后的路径追加到目标URL
+	shortLinks.GET("/:code/*rest", handler.RedirectShortLink)
 }
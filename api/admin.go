@@ -1,17 +1,21 @@
 package api
 
 import (
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/handlers"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/ratelimit"
 	"github.com/qiuxsgit/go-short-link/utils"
+	"gorm.io/gorm"
 )
 
-// JWTAuthMiddleware 创建JWT认证中间件
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware 创建JWT认证中间件，已吊销的令牌视为无效
+func JWTAuthMiddleware(revocation *utils.TokenRevocation) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从请求头获取Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -30,21 +34,164 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		}
 
 		// 解析令牌
-		tokenString := parts[1]
-		claims, err := utils.ParseToken(tokenString)
-		if err != nil {
-			if err == utils.ErrExpiredToken {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "认证令牌已过期"})
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "认证令牌无效"})
+		if !authenticateToken(c, revocation, parts[1]) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticateToken 解析访问令牌并检查吊销列表，成功时将用户信息存储在上下文中，失败时返回错误响应
+func authenticateToken(c *gin.Context, revocation *utils.TokenRevocation, tokenString string) bool {
+	claims, err := utils.ParseToken(tokenString)
+	if err != nil {
+		if err == utils.ErrExpiredToken {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "认证令牌已过期"})
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "认证令牌无效"})
+		}
+		c.Abort()
+		return false
+	}
+
+	revoked, err := revocation.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		log.Printf("检查令牌吊销状态失败: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "认证服务暂不可用"})
+		c.Abort()
+		return false
+	}
+	// 升级前签发的令牌没有角色，需要刷新后重新获取
+	if revoked || claims.Role == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "认证令牌已失效"})
+		c.Abort()
+		return false
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("workspaceID", claims.WorkspaceID)
+	c.Set("claims", claims)
+	return true
+}
+
+// CreateAuthMiddleware 创建短链接接口的认证中间件
+// 支持 X-API-Key 或 Authorization: Bearer 携带的API密钥，以及管理员登录令牌；
+// 提供了凭证时必须有效，required为true时必须提供凭证
+func CreateAuthMiddleware(db *gorm.DB, revocation *utils.TokenRevocation, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := c.GetHeader("X-API-Key")
+		if credential == "" {
+			parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+			if len(parts) == 2 && parts[0] == "Bearer" {
+				credential = parts[1]
+			}
+		}
+
+		if credential == "" {
+			if required {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "未提供API密钥或认证令牌"})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if models.IsAPIKey(credential) {
+			key, err := models.AuthenticateAPIKey(db, credential)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
 			}
+			if !key.HasScope(models.ScopeLinkCreate) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "API密钥无权创建短链接"})
+				c.Abort()
+				return
+			}
+			c.Set("apiKeyID", key.ID)
+			c.Set("workspaceID", key.WorkspaceID)
+			c.Next()
+			return
+		}
+
+		if !authenticateToken(c, revocation, credential) {
+			return
+		}
+		if !models.RoleAtLeast(c.GetString("role"), models.RoleEditor) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "当前角色无权创建短链接"})
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		// 将用户信息存储在上下文中
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
+// RequireRole 创建角色权限中间件，要求登录用户的角色不低于min，必须在JWTAuthMiddleware之后使用
+func RequireRole(min string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.RoleAtLeast(c.GetString("role"), min) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AccountSetupMiddleware 要求使用初始密码的管理员先修改密码，系统要求两步验证时要求未绑定的管理员先完成绑定
+// 修改密码和两步验证相关接口不受限制
+func AccountSetupMiddleware(db *gorm.DB, twoFactorRequired bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "/api/change-password" || path == "/api/logout" {
+			c.Next()
+			return
+		}
+
+		userID, _ := c.Get("userID")
+		var admin models.SysAdmin
+		if err := db.Select("must_change_password", "totp_enabled").Where("id = ?", userID).First(&admin).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "用户不存在"})
+			c.Abort()
+			return
+		}
+		if admin.MustChangePassword {
+			c.JSON(http.StatusForbidden, gin.H{"error": "请先修改初始密码", "mustChangePassword": true})
+			c.Abort()
+			return
+		}
+		if twoFactorRequired && !admin.TOTPEnabled && !strings.HasPrefix(path, "/api/2fa/") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "请先启用两步验证", "twoFactorSetupRequired": true})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// PlatformOwnerMiddleware 要求当前用户为平台所有者（默认工作区的owner），用于管理工作区等全局资源
+func PlatformOwnerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.IsPlatformOwner(c.GetInt64("workspaceID"), c.GetString("role")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// DefaultWorkspaceMiddleware 要求当前用户属于默认工作区，用于所有工作区共用的全局配置
+func DefaultWorkspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetInt64("workspaceID") != models.DefaultWorkspaceID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "权限不足"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -63,38 +210,138 @@ func IPWhitelistMiddleware(config *conf.AdminServerConfig) gin.HandlerFunc {
 }
 
 // SetupAdminRoutes 设置管理API路由
-func SetupAdminRoutes(router *gin.Engine, shortLinkHandler *handlers.ShortLinkHandler, adminHandler *handlers.AdminHandler, config *conf.AdminServerConfig) {
+func SetupAdminRoutes(router *gin.Engine, shortLinkHandler *handlers.ShortLinkHandler, adminHandler *handlers.AdminHandler, store *models.GormStore, limiter *ratelimit.Limiter, revocation *utils.TokenRevocation, config *conf.Config) {
 	// 添加IP白名单中间件
-	// router.Use(IPWhitelistMiddleware(config))
+	// router.Use(IPWhitelistMiddleware(&config.Server.Admin))
+
+	loginLimit := RateLimitMiddleware(limiter, "login", ratelimit.RuleFromConfig(config.RateLimit.Login), nil)
+	createLimit := RateLimitMiddleware(limiter, "create", ratelimit.RuleFromConfig(config.RateLimit.Create), nil)
 
 	// 公共API路由（无需认证）
 	publicAPI := router.Group("/api")
 	{
 		// 管理员登录
-		publicAPI.POST("/login", adminHandler.Login)
+		publicAPI.POST("/login", loginLimit, adminHandler.Login)
 
-		// 创建短链接（保留原有功能）
-		publicAPI.POST("/short-link/create", shortLinkHandler.CreateShortLink)
+		// 登录第二步：提交两步验证码
+		publicAPI.POST("/login/2fa", loginLimit, adminHandler.LoginTwoFactor)
+
+		// 使用刷新令牌换取新的访问令牌
+		publicAPI.POST("/token/refresh", loginLimit, adminHandler.RefreshToken)
+
+		// 创建短链接，可使用API密钥或登录令牌认证，是否必须认证由配置决定；认证后按API密钥或用户限流
+		publicAPI.POST("/short-link/create", CreateAuthMiddleware(store.GetDB(), revocation, config.Server.Admin.RequireAuthForCreate), createLimit, shortLinkHandler.CreateShortLink)
 	}
 
-	// 需要认证的API路由
+	// 各角色的权限
+	viewer := RequireRole(models.RoleViewer)
+	editor := RequireRole(models.RoleEditor)
+	admin := RequireRole(models.RoleAdmin)
+	platform := DefaultWorkspaceMiddleware()
+
+	// 需要认证的API路由，所有角色都可以修改自己的密码、管理自己的两步验证
 	privateAPI := router.Group("/api")
-	privateAPI.Use(JWTAuthMiddleware())
+	privateAPI.Use(JWTAuthMiddleware(revocation), AccountSetupMiddleware(store.GetDB(), config.TwoFactor.Required))
 	{
 		// 用户管理
 		privateAPI.POST("/change-password", adminHandler.ChangePassword)
+		privateAPI.POST("/logout", adminHandler.Logout)
+
+		// 两步验证
+		twoFactorAPI := privateAPI.Group("/2fa")
+		{
+			twoFactorAPI.GET("/status", adminHandler.GetTwoFactorStatus)
+			twoFactorAPI.POST("/enroll", adminHandler.EnrollTwoFactor)
+			twoFactorAPI.POST("/verify", adminHandler.VerifyTwoFactor)
+			twoFactorAPI.POST("/recovery-codes", adminHandler.RegenerateRecoveryCodes)
+			twoFactorAPI.POST("/disable", adminHandler.DisableTwoFactor)
+		}
 
-		// 短链接管理
+		// 工作区管理，当前工作区信息所有角色可见，其余只有平台所有者可以操作
+		privateAPI.GET("/workspace/current", adminHandler.GetCurrentWorkspace)
+		workspaceAPI := privateAPI.Group("/workspace", PlatformOwnerMiddleware())
+		{
+			workspaceAPI.GET("/list", adminHandler.GetWorkspaces)
+			workspaceAPI.POST("", adminHandler.CreateWorkspace)
+			workspaceAPI.PUT("/:id", adminHandler.UpdateWorkspace)
+			workspaceAPI.DELETE("/:id", adminHandler.DeleteWorkspace)
+		}
+
+		// 配额使用情况
+		privateAPI.GET("/quota/usage", admin, adminHandler.GetQuotaUsage)
+
+		// 管理员账户和登录审计
+		adminAPI := privateAPI.Group("/admin", admin)
+		{
+			adminAPI.GET("/list", adminHandler.GetAdmins)
+			adminAPI.POST("", adminHandler.CreateAdmin)
+			adminAPI.PUT("/:id", adminHandler.UpdateAdmin)
+			adminAPI.DELETE("/:id", adminHandler.DeleteAdmin)
+			adminAPI.POST("/:id/unlock", adminHandler.UnlockAdmin)
+		}
+		privateAPI.GET("/login-audit/list", admin, adminHandler.GetLoginAudits)
+
+		// 短链接管理，查看者只能浏览和查看统计
 		linkAPI := privateAPI.Group("/short-link")
 		{
 			// 获取有效短链接列表
-			linkAPI.GET("/list", adminHandler.GetShortLinks)
+			linkAPI.GET("/list", viewer, adminHandler.GetShortLinks)
 
 			// 获取历史短链接列表
-			linkAPI.GET("/history", adminHandler.GetHistoryLinks)
+			linkAPI.GET("/history", viewer, adminHandler.GetHistoryLinks)
+
+			// 编辑短链接
+			linkAPI.PUT("/:id", editor, adminHandler.UpdateShortLink)
+
+			// 启用、停用或隔离短链接
+			linkAPI.PUT("/:id/status", editor, adminHandler.UpdateLinkStatus)
+
+			// A/B分流目标管理
+			linkAPI.GET("/:id/variants", viewer, adminHandler.GetVariants)
+			linkAPI.PUT("/:id/variants", editor, adminHandler.SetVariants)
+
+			// 短链接二维码
+			linkAPI.GET("/:id/qr", viewer, adminHandler.GetQRCode)
+
+			// 点击统计
+			linkAPI.GET("/:id/stats", viewer, adminHandler.GetLinkStats)
 
 			// 删除短链接（移动到历史表）
-			linkAPI.DELETE("/:id", adminHandler.DeleteShortLink)
+			linkAPI.DELETE("/:id", editor, adminHandler.DeleteShortLink)
+		}
+
+		// 短链接域名管理
+		domainAPI := privateAPI.Group("/domain")
+		{
+			domainAPI.GET("/list", viewer, adminHandler.GetDomains)
+			domainAPI.POST("", admin, adminHandler.CreateDomain)
+			domainAPI.PUT("/:id", admin, adminHandler.UpdateDomain)
+			domainAPI.DELETE("/:id", admin, adminHandler.DeleteDomain)
+		}
+
+		// 目标域名策略管理，策略对所有工作区生效，只能由默认工作区的管理员修改
+		policyAPI := privateAPI.Group("/domain-policy")
+		{
+			policyAPI.GET("/list", viewer, adminHandler.GetDomainPolicies)
+			policyAPI.GET("/check", viewer, adminHandler.CheckDomainPolicy)
+			policyAPI.POST("", platform, admin, adminHandler.CreateDomainPolicy)
+			policyAPI.DELETE("/:id", platform, admin, adminHandler.DeleteDomainPolicy)
+		}
+
+		// 举报审核
+		reportAPI := privateAPI.Group("/report", editor)
+		{
+			reportAPI.GET("/list", adminHandler.GetReports)
+			reportAPI.POST("/:id/dismiss", adminHandler.DismissReport)
+			reportAPI.POST("/:id/disable", adminHandler.DisableReportedLink)
+		}
+
+		// API密钥管理
+		apiKeyAPI := privateAPI.Group("/api-key", admin)
+		{
+			apiKeyAPI.GET("/list", adminHandler.GetAPIKeys)
+			apiKeyAPI.POST("", adminHandler.CreateAPIKey)
+			apiKeyAPI.DELETE("/:id", adminHandler.RevokeAPIKey)
 		}
 	}
 }
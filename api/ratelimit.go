@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/ratelimit"
+)
+
+// RateLimitMiddleware 创建令牌桶限流中间件，name区分不同接口的限额
+// 响应中携带 RateLimit-* 头，超出限额时返回429和Retry-After，onLimited为nil时返回JSON错误
+func RateLimitMiddleware(limiter *ratelimit.Limiter, name string, rule ratelimit.Rule, onLimited gin.HandlerFunc) gin.HandlerFunc {
+	if !rule.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		result := limiter.Allow(c.Request.Context(), name, rateLimitKey(c), rule)
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			if onLimited != nil {
+				onLimited(c)
+			} else {
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": "请求过于频繁，请稍后再试"})
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey 选择限流计数对象：API密钥优先，其次是登录用户，最后是客户端IP
+func rateLimitKey(c *gin.Context) string {
+	if keyID, ok := c.Get("apiKeyID"); ok {
+		return fmt.Sprintf("key:%v", keyID)
+	}
+	if userID, ok := c.Get("userID"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds 将时长向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/qiuxsgit/go-short-link/quota"
+	"github.com/qiuxsgit/go-short-link/ratelimit"
 	"github.com/qiuxsgit/go-short-link/tasks"
 	"github.com/qiuxsgit/go-short-link/utils"
 	"github.com/redis/go-redis/v9"
@@ -21,6 +25,10 @@ type App struct {
 	RedisClient       *redis.Client
 	IDGeneratorPlugin *utils.RedisIDGenerator
 	TaskScheduler     *tasks.Scheduler
+	Policy            *policy.Engine
+	RateLimiter       *ratelimit.Limiter
+	Quota             *quota.Manager
+	TokenRevocation   *utils.TokenRevocation
 	DB                *gorm.DB
 }
 
@@ -37,8 +45,22 @@ func Initialize() (*App, error) {
 		return nil, err
 	}
 
-	// 设置JWT密钥
-	utils.SetJWTSecret(config.JWT.Secret)
+	// 检查默认跳转方式，与短链接单独设置的跳转方式使用相同的校验规则
+	if !models.IsValidRedirectType(config.Server.Access.RedirectType) {
+		return nil, fmt.Errorf("不支持的默认跳转方式server.access.redirectType: %s", config.Server.Access.RedirectType)
+	}
+
+	// 兼容旧的令牌有效期配置
+	if warning := config.JWT.ApplyLegacyExpireHours(); warning != "" {
+		log.Printf("警告: %s", warning)
+	}
+
+	// 加载JWT签名密钥
+	keySet, err := loadJWTKeys(config)
+	if err != nil {
+		return nil, err
+	}
+	utils.SetKeySet(keySet)
 
 	// 创建Redis客户端
 	redisClient := redis.NewClient(&redis.Options{
@@ -66,11 +88,16 @@ func Initialize() (*App, error) {
 	// 获取GORM DB实例
 	db := gormStore.GetDB()
 
-	// 确保管理员表存在
-	if err := db.AutoMigrate(&models.SysAdmin{}); err != nil {
+	// 确保工作区表、管理员表、恢复码表、刷新令牌表、登录审计表和API密钥表存在
+	if err := db.AutoMigrate(&models.Workspace{}, &models.SysAdmin{}, &models.AdminRecoveryCode{}, &models.RefreshToken{}, &models.LoginAudit{}, &models.APIKey{}); err != nil {
 		return nil, fmt.Errorf("自动迁移管理员表失败: %v", err)
 	}
 
+	// 同步历史表结构，历史短链接按工作区和创建者筛选
+	if err := models.MigrateHistoryTables(db, config.Tasks.CleanExpiredLinks.HistoryTablePrefix); err != nil {
+		return nil, err
+	}
+
 	// 确保至少存在一个管理员账户
 	initialPassword, err := models.EnsureAdminExists(db)
 	if err != nil {
@@ -79,9 +106,18 @@ func Initialize() (*App, error) {
 
 	// 如果生成了初始密码，打印出来
 	if initialPassword != "" {
-		log.Printf("已创建初始管理员账户，用户名: admin，密码: %s", initialPassword)
+		log.Printf("已创建初始管理员账户，用户名: admin，密码: %s（首次登录后必须修改密码）", initialPassword)
+	}
+
+	// 创建目标域名策略引擎
+	policyEngine, err := policy.NewEngine(&config.Policy, db)
+	if err != nil {
+		return nil, fmt.Errorf("加载域名策略失败: %v", err)
 	}
 
+	// 创建短链接配额管理器
+	quotaManager := quota.NewManager(redisClient, db, &config.Quota)
+
 	// 创建定时任务调度器
 	taskScheduler := tasks.NewScheduler(config)
 
@@ -91,16 +127,78 @@ func Initialize() (*App, error) {
 		taskScheduler.RegisterTask(cleanTask)
 	}
 
+	// 注册按域名策略扫描短链接任务
+	if config.Tasks.RescanLinks.Enabled {
+		rescanTask := tasks.NewRescanLinksTask(&config.Tasks.RescanLinks, gormStore, policyEngine)
+		taskScheduler.RegisterTask(rescanTask)
+	}
+
+	// 注册配额校准任务
+	if config.Tasks.ReconcileQuotas.Enabled {
+		reconcileTask := tasks.NewReconcileQuotasTask(&config.Tasks.ReconcileQuotas, quotaManager)
+		taskScheduler.RegisterTask(reconcileTask)
+	}
+
 	return &App{
 		Config:            config,
 		Store:             gormStore,
 		RedisClient:       redisClient,
 		IDGeneratorPlugin: idGeneratorPlugin,
 		TaskScheduler:     taskScheduler,
+		Policy:            policyEngine,
+		RateLimiter:       ratelimit.NewLimiter(redisClient, config.RateLimit.KeyPrefix),
+		Quota:             quotaManager,
+		TokenRevocation:   utils.NewTokenRevocation(redisClient, "", config.JWT.AccessTokenDuration()),
 		DB:                db,
 	}, nil
 }
 
+// loadJWTKeys 按配置加载JWT签名密钥集合，release模式下拒绝使用默认密钥
+func loadJWTKeys(config *conf.Config) (*utils.KeySet, error) {
+	jwtConfig := config.JWT
+
+	var keys []*utils.SigningKey
+	activeKID := jwtConfig.ActiveKID
+	if len(jwtConfig.Keys) == 0 {
+		key, err := utils.NewHMACKey(utils.LegacyKeyID, jwtConfig.Secret)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		activeKID = utils.LegacyKeyID
+	}
+	for _, keyConfig := range jwtConfig.Keys {
+		key, err := utils.LoadSigningKey(keyConfig.KID, keyConfig.Algorithm, keyConfig.Secret, keyConfig.PrivateKeyFile, keyConfig.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if keyConfig.RetireAt != "" {
+			retireAt, err := time.Parse(time.RFC3339, keyConfig.RetireAt)
+			if err != nil {
+				return nil, fmt.Errorf("JWT密钥%s的退役时间格式无效: %v", keyConfig.KID, err)
+			}
+			key.RetireAt = retireAt
+		}
+		keys = append(keys, key)
+	}
+
+	keySet, err := utils.NewKeySet(activeKID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Server.GinMode == "release" {
+		for _, key := range keySet.Keys() {
+			if key.IsDefaultSecret() {
+				return nil, fmt.Errorf("release模式下禁止使用默认JWT密钥，请修改jwt配置")
+			}
+		}
+	} else if keySet.Active().IsDefaultSecret() {
+		log.Printf("警告: 正在使用默认JWT密钥，生产环境请修改jwt配置")
+	}
+	return keySet, nil
+}
+
 // Cleanup 清理应用程序资源
 func (a *App) Cleanup() {
 	// 停止定时任务调度器
@@ -6,6 +6,8 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +20,15 @@ type Config struct {
 	Cache    CacheConfig    `yaml:"cache"`
 	Tasks    TasksConfig    `yaml:"tasks"`
 	JWT      JWTConfig      `yaml:"jwt"`
+	Policy   PolicyConfig   `yaml:"domainPolicy"`
+	// RateLimit 接口限流配置
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	// LoginSecurity 登录防暴力破解配置
+	LoginSecurity LoginSecurityConfig `yaml:"loginSecurity"`
+	// TwoFactor 两步验证配置
+	TwoFactor TwoFactorConfig `yaml:"twoFactor"`
+	// Quota 短链接配额配置
+	Quota QuotaConfig `yaml:"quota"`
 }
 
 // ServerConfig 服务器配置
@@ -32,12 +43,126 @@ type AdminServerConfig struct {
 	Port        int      `yaml:"port"`
 	BaseURL     string   `yaml:"baseURL"`
 	IPWhitelist []string `yaml:"ipWhitelist"`
+	// RequireAuthForCreate 创建短链接时是否必须提供API密钥或登录令牌
+	RequireAuthForCreate bool `yaml:"requireAuthForCreate"`
 }
 
 // AccessServerConfig 访问API服务配置
 type AccessServerConfig struct {
 	Port    int    `yaml:"port"`
 	BaseURL string `yaml:"baseURL"`
+	// PathPrefix 短链接路径前缀，未配置时为"s"（/s/:code），配置为空字符串时短链接位于域名根路径（/:code）
+	PathPrefix *string `yaml:"pathPrefix"`
+	// FallbackURL 默认域名下短链接不存在时跳转的地址，为空时返回404页面
+	FallbackURL string `yaml:"fallbackURL"`
+	// DisabledPageURL 访问已停用或隔离的短链接时跳转的地址，为空时返回内置的停用提示页面
+	DisabledPageURL string `yaml:"disabledPageURL"`
+	// RedirectType 默认跳转方式: 301、302、307、308或meta，短链接未单独设置时使用
+	RedirectType string `yaml:"redirectType"`
+	// PermanentCacheSeconds 永久跳转（301/308）允许客户端缓存的最长时间（秒）
+	PermanentCacheSeconds int          `yaml:"permanentCacheSeconds"`
+	QRCode                QRCodeConfig `yaml:"qrcode"`
+	// TemplateDir 自定义页面模板目录，为空时只使用内置模板
+	TemplateDir string `yaml:"templateDir"`
+	// DefaultLanguage 无法从Accept-Language匹配时使用的页面语言: zh或en
+	DefaultLanguage string `yaml:"defaultLanguage"`
+	// URLValidation 目标URL校验配置
+	URLValidation URLValidationConfig `yaml:"urlValidation"`
+	// Report 访问者举报配置
+	Report ReportConfig `yaml:"report"`
+}
+
+// TwoFactorConfig 两步验证（TOTP）配置
+type TwoFactorConfig struct {
+	// Required 为true时所有管理员都必须启用两步验证，未启用的管理员登录后只能进行绑定
+	Required bool `yaml:"required"`
+	// Issuer 验证器App中显示的发行方名称，默认"go-short-link"
+	Issuer string `yaml:"issuer"`
+	// PreAuthMinutes 密码验证通过后提交验证码的有效时间（分钟），默认5
+	PreAuthMinutes int `yaml:"preAuthMinutes"`
+}
+
+// LoginSecurityConfig 登录防暴力破解配置
+type LoginSecurityConfig struct {
+	DelayAfter      int `yaml:"delayAfter"`      // 连续失败多少次后开始要求等待，默认3
+	MaxDelaySeconds int `yaml:"maxDelaySeconds"` // 每次等待时间翻倍，最长等待秒数，默认60
+	MaxAttempts     int `yaml:"maxAttempts"`     // 连续失败多少次后锁定账户，默认10
+	LockoutMinutes  int `yaml:"lockoutMinutes"`  // 账户锁定时长（分钟），默认15
+	IPMaxAttempts   int `yaml:"ipMaxAttempts"`   // 同一IP在时间窗口内最多失败次数，默认30
+	IPWindowMinutes int `yaml:"ipWindowMinutes"` // IP失败次数的统计窗口（分钟），默认15
+}
+
+// ReconcileQuotasConfig 按数据库校准配额计数任务配置
+type ReconcileQuotasConfig struct {
+	Cron    string `yaml:"cron"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+// QuotaConfig 短链接配额配置
+// 计数保存在Redis中，由定时任务按数据库校准；Redis不可用时直接查询数据库
+type QuotaConfig struct {
+	KeyPrefix string `yaml:"keyPrefix"` // Redis键前缀，默认"quota:"
+	// User 每个管理员或API密钥的配额
+	User QuotaLimits `yaml:"user"`
+	// Workspace 每个工作区的默认配额，工作区可以单独设置
+	Workspace QuotaLimits `yaml:"workspace"`
+}
+
+// QuotaLimits 配额限制，各项为0时不限制
+type QuotaLimits struct {
+	MaxActiveLinks  int64 `yaml:"maxActiveLinks"`  // 最多同时有效（未过期）的短链接数
+	MaxDailyCreates int64 `yaml:"maxDailyCreates"` // 每天最多创建的短链接数，按服务器时区的自然日计算
+	MaxTTLSeconds   int64 `yaml:"maxTTLSeconds"`   // 短链接有效期上限（秒）
+}
+
+// RateLimitConfig 接口限流配置
+// 限流按API密钥、登录用户、客户端IP的顺序选择计数对象，状态保存在Redis中，Redis不可用时使用内存计数
+type RateLimitConfig struct {
+	KeyPrefix string        `yaml:"keyPrefix"` // Redis键前缀，默认"ratelimit:"
+	Create    RateLimitRule `yaml:"create"`    // 创建短链接
+	Login     RateLimitRule `yaml:"login"`     // 管理员登录
+	Redirect  RateLimitRule `yaml:"redirect"`  // 短链接跳转
+}
+
+// RateLimitRule 令牌桶规则，Requests为0时不限流
+type RateLimitRule struct {
+	Requests      int `yaml:"requests"`      // 每个周期补充的令牌数
+	PeriodSeconds int `yaml:"periodSeconds"` // 周期（秒），默认60
+	Burst         int `yaml:"burst"`         // 令牌桶容量，默认等于requests
+}
+
+// ReportConfig 访问者举报配置
+type ReportConfig struct {
+	MaxPerIP            int `yaml:"maxPerIP"`            // 每个IP在时间窗口内最多提交的举报数，默认10
+	WindowSeconds       int `yaml:"windowSeconds"`       // 限流时间窗口（秒），默认3600，按令牌桶平滑补充
+	QuarantineThreshold int `yaml:"quarantineThreshold"` // 自动隔离所需的不同举报人数，为0时不自动隔离，短链接可单独设置
+}
+
+// URLValidationConfig 目标URL校验配置
+type URLValidationConfig struct {
+	AllowedSchemes    []string `yaml:"allowedSchemes"`    // 允许的协议，为空时只允许http和https
+	MaxLength         int      `yaml:"maxLength"`         // URL最大长度，默认2048
+	AllowPrivateHosts bool     `yaml:"allowPrivateHosts"` // 是否允许指向内网、回环和链路本地地址
+	// DeepLinkSchemes 设备规则中允许的App深度链接协议（如myapp、intent），为空时允许除http(s)、javascript、data等以外的任意协议
+	DeepLinkSchemes []string `yaml:"deepLinkSchemes"`
+}
+
+// DefaultPathPrefix 未配置时使用的短链接路径前缀
+const DefaultPathPrefix = "s"
+
+// ShortPathPrefix 返回去掉首尾斜杠的短链接路径前缀
+func (c *AccessServerConfig) ShortPathPrefix() string {
+	if c.PathPrefix == nil {
+		return DefaultPathPrefix
+	}
+	return strings.Trim(*c.PathPrefix, "/")
+}
+
+// QRCodeConfig 二维码生成配置
+type QRCodeConfig struct {
+	DefaultSize int    `yaml:"defaultSize"` // 默认图片边长（像素）
+	MaxSize     int    `yaml:"maxSize"`     // 允许请求的最大图片边长（像素）
+	LogoDir     string `yaml:"logoDir"`     // 中心Logo图片所在目录，为空时不支持Logo
 }
 
 // DatabaseConfig 数据库配置
@@ -75,6 +200,8 @@ type CacheConfig struct {
 // TasksConfig 定时任务配置
 type TasksConfig struct {
 	CleanExpiredLinks CleanExpiredLinksConfig `yaml:"cleanExpiredLinks"`
+	RescanLinks       RescanLinksConfig       `yaml:"rescanLinks"`
+	ReconcileQuotas   ReconcileQuotasConfig   `yaml:"reconcileQuotas"`
 }
 
 // CleanExpiredLinksConfig 清理过期短链接任务配置
@@ -85,10 +212,81 @@ type CleanExpiredLinksConfig struct {
 	HistoryTablePrefix string `yaml:"historyTablePrefix"`
 }
 
+// RescanLinksConfig 按域名策略重新扫描短链接任务配置
+type RescanLinksConfig struct {
+	Cron      string `yaml:"cron"`
+	Enabled   bool   `yaml:"enabled"`
+	BatchSize int    `yaml:"batchSize"`
+}
+
+// PolicyConfig 目标域名策略配置
+// 域名规则支持精确匹配（example.com）和通配符（*.example.com，匹配所有子域名）
+type PolicyConfig struct {
+	Allow         []string `yaml:"allow"`         // 允许的域名，优先于拒绝规则和恶意域名库
+	Deny          []string `yaml:"deny"`          // 拒绝的域名
+	AllowlistOnly bool     `yaml:"allowlistOnly"` // 为true时只允许命中允许规则的域名
+	FeedFile      string   `yaml:"feedFile"`      // 本地恶意域名库文件，每行一个域名，#开头为注释
+	ReloadSeconds int      `yaml:"reloadSeconds"` // 管理规则和恶意域名库的刷新间隔（秒），默认60
+}
+
 // JWTConfig JWT配置
+// 只配置secret时使用单个HS256密钥；配置keys时按activeKid选择签发新令牌的密钥，其余密钥只用于校验
 type JWTConfig struct {
-	Secret      string `yaml:"secret"`
-	ExpireHours int    `yaml:"expireHours"`
+	Secret string `yaml:"secret"`
+	// ActiveKID 签发新令牌使用的密钥ID
+	ActiveKID string `yaml:"activeKid"`
+	// Keys 签名密钥集合
+	Keys []JWTKeyConfig `yaml:"keys"`
+	// AccessTokenMinutes 访问令牌有效期（分钟），默认15
+	AccessTokenMinutes int `yaml:"accessTokenMinutes"`
+	// RefreshTokenHours 刷新令牌有效期（小时），默认168（7天）
+	RefreshTokenHours int `yaml:"refreshTokenHours"`
+	// ExpireHours 已废弃，旧版本的令牌有效期（小时），见ApplyLegacyExpireHours
+	ExpireHours int `yaml:"expireHours"`
+}
+
+// ApplyLegacyExpireHours 兼容旧配置：未配置accessTokenMinutes时将expireHours换算为访问令牌有效期
+// 配置了expireHours时返回弃用警告，否则返回空字符串
+func (c *JWTConfig) ApplyLegacyExpireHours() string {
+	if c.ExpireHours <= 0 {
+		return ""
+	}
+	if c.AccessTokenMinutes > 0 {
+		return "jwt.expireHours已废弃并被忽略，访问令牌有效期使用jwt.accessTokenMinutes"
+	}
+	c.AccessTokenMinutes = c.ExpireHours * 60
+	return fmt.Sprintf("jwt.expireHours已废弃，访问令牌有效期暂按其值设为%d分钟，请改用jwt.accessTokenMinutes和jwt.refreshTokenHours", c.AccessTokenMinutes)
+}
+
+// JWTKeyConfig JWT签名密钥配置
+type JWTKeyConfig struct {
+	KID string `yaml:"kid"` // 密钥ID，写入令牌的kid头
+	// Algorithm 签名算法: HS256、RS256或EdDSA，默认HS256
+	Algorithm string `yaml:"algorithm"`
+	// Secret HS256密钥
+	Secret string `yaml:"secret"`
+	// PrivateKeyFile RS256/EdDSA的PEM私钥文件，只用于校验的旧密钥可以不配置
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+	// PublicKeyFile RS256/EdDSA的PEM公钥文件，未配置时从私钥推导
+	PublicKeyFile string `yaml:"publicKeyFile"`
+	// RetireAt 退役时间（RFC3339格式），此后不再接受该密钥签发的令牌，为空表示不退役
+	RetireAt string `yaml:"retireAt"`
+}
+
+// AccessTokenDuration 返回访问令牌有效期
+func (c *JWTConfig) AccessTokenDuration() time.Duration {
+	if c.AccessTokenMinutes <= 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(c.AccessTokenMinutes) * time.Minute
+}
+
+// RefreshTokenDuration 返回刷新令牌有效期
+func (c *JWTConfig) RefreshTokenDuration() time.Duration {
+	if c.RefreshTokenHours <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(c.RefreshTokenHours) * time.Hour
 }
 
 // LoadConfig 从文件加载配置
@@ -160,3 +358,38 @@ func (c *AdminServerConfig) IsIPAllowed(ip string) bool {
 
 	return false
 }
+
+// RateLimitRule 返回举报接口的限流规则
+func (c *ReportConfig) RateLimitRule() RateLimitRule {
+	rule := RateLimitRule{Requests: c.MaxPerIP, PeriodSeconds: c.WindowSeconds}
+	if rule.Requests <= 0 {
+		rule.Requests = 10
+	}
+	if rule.PeriodSeconds <= 0 {
+		rule.PeriodSeconds = 3600
+	}
+	return rule
+}
+
+// WithDefaults 返回填充了默认值的登录防暴力破解配置
+func (c LoginSecurityConfig) WithDefaults() LoginSecurityConfig {
+	if c.DelayAfter <= 0 {
+		c.DelayAfter = 3
+	}
+	if c.MaxDelaySeconds <= 0 {
+		c.MaxDelaySeconds = 60
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 10
+	}
+	if c.LockoutMinutes <= 0 {
+		c.LockoutMinutes = 15
+	}
+	if c.IPMaxAttempts <= 0 {
+		c.IPMaxAttempts = 30
+	}
+	if c.IPWindowMinutes <= 0 {
+		c.IPWindowMinutes = 15
+	}
+	return c
+}
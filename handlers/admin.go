@@ -7,20 +7,66 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/qiuxsgit/go-short-link/quota"
 	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // AdminHandler 处理管理员相关的请求
 type AdminHandler struct {
-	db     *models.GormStore
-	config *conf.Config
+	db         *models.GormStore
+	config     *conf.Config
+	policy     *policy.Engine
+	quota      *quota.Manager
+	revocation *utils.TokenRevocation
 }
 
 // NewAdminHandler 创建一个新的管理员处理器
-func NewAdminHandler(db *models.GormStore, config *conf.Config) *AdminHandler {
+func NewAdminHandler(db *models.GormStore, config *conf.Config, engine *policy.Engine, quotas *quota.Manager, revocation *utils.TokenRevocation) *AdminHandler {
 	return &AdminHandler{
-		db:     db,
-		config: config,
+		db:         db,
+		config:     config,
+		policy:     engine,
+		quota:      quotas,
+		revocation: revocation,
+	}
+}
+
+// inWorkspace 将查询限定在当前用户或API密钥所属的工作区
+func inWorkspace(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return models.InWorkspace(c.GetInt64("workspaceID"))
+}
+
+// visibleLinks 将短链接查询限定在当前工作区，并按当前用户的角色限定浏览范围
+func visibleLinks(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = inWorkspace(c)(db)
+		return models.VisibleLinks(c.GetString("role"), c.GetInt64("userID"))(db)
+	}
+}
+
+// ownedLinks 将短链接查询限定在当前工作区，并按当前用户的角色限定修改范围
+func ownedLinks(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = inWorkspace(c)(db)
+		return models.OwnedLinks(c.GetString("role"), c.GetInt64("userID"))(db)
+	}
+}
+
+// isPlatformOwner 当前用户是否为平台所有者（默认工作区的owner）
+func isPlatformOwner(c *gin.Context) bool {
+	return models.IsPlatformOwner(c.GetInt64("workspaceID"), c.GetString("role"))
+}
+
+// adminScope 限定管理员账户的查询范围，平台所有者可以访问所有工作区的账户
+func adminScope(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if isPlatformOwner(c) {
+			return db
+		}
+		return inWorkspace(c)(db)
 	}
 }
 
@@ -35,6 +81,19 @@ type LoginResponse struct {
 	Token    string `json:"token"`
 	Username string `json:"username"`
 	UserID   int64  `json:"userId"`
+	// Role 管理员角色，WorkspaceID 所属工作区
+	Role        string `json:"role,omitempty"`
+	WorkspaceID int64  `json:"workspaceId"`
+	// RefreshToken 用于换取新访问令牌的刷新令牌，ExpiresIn为访问令牌的有效秒数
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int64  `json:"expiresIn,omitempty"`
+	// MustChangePassword 为true时需要先修改密码才能使用其他管理接口
+	MustChangePassword bool `json:"mustChangePassword"`
+	// TwoFactorSetupRequired 为true时需要先绑定两步验证才能使用其他管理接口
+	TwoFactorSetupRequired bool `json:"twoFactorSetupRequired"`
+	// TwoFactorRequired 为true时需要使用PreAuthToken提交两步验证码完成登录
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	PreAuthToken      string `json:"preAuthToken,omitempty"`
 }
 
 // ChangePasswordRequest 修改密码请求结构
@@ -45,6 +104,8 @@ type ChangePasswordRequest struct {
 }
 
 // Login 管理员登录
+// 连续失败后需要等待的时间逐次翻倍，达到上限后临时锁定账户；同一IP失败次数过多时拒绝登录。
+// 启用了两步验证的账户在密码验证通过后返回中间令牌，需要再调用 LoginTwoFactor 提交验证码
 func (h *AdminHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -52,48 +113,97 @@ func (h *AdminHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// 查询管理员
+	security := h.config.LoginSecurity.WithDefaults()
+	now := time.Now()
+
+	// 检查来源IP的失败次数
+	if !h.checkLoginIP(c, req.Username, security, now) {
+		return
+	}
+
+	// 查询管理员，用户名不存在时同样校验一次密码，使响应耗时一致
 	var admin models.SysAdmin
 	if err := h.db.GetDB().Where("username = ?", req.Username).First(&admin).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
+		models.CheckPassword(dummyPasswordHash(), req.Password)
+		h.auditLogin(c, 0, req.Username, models.LoginResultUnknownUser)
+		respondLoginFailed(c)
+		return
+	}
+	passwordOK := models.CheckPassword(admin.Password, req.Password)
+
+	// 检查账户锁定和失败等待时间，响应与密码错误相同
+	if result, _ := accountBlocked(&admin, security, now); result != "" {
+		h.auditLogin(c, admin.ID, admin.Username, result)
+		respondLoginFailed(c)
 		return
 	}
 
 	// 验证密码
-	if !models.CheckPassword(admin.Password, req.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
+	if !passwordOK {
+		result := h.recordLoginFailure(&admin, security, now, models.LoginResultBadPassword)
+		h.auditLogin(c, admin.ID, admin.Username, result)
+		respondLoginFailed(c)
 		return
 	}
 
-	// 更新最后登录时间
-	h.db.GetDB().Model(&admin).Update("last_login", time.Now())
+	// 启用了两步验证时返回中间令牌
+	if admin.TOTPEnabled {
+		preAuthToken, err := utils.GeneratePreAuthToken(admin.ID, admin.Username, h.preAuthDuration())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+			return
+		}
+		c.JSON(http.StatusOK, LoginResponse{
+			Username:          admin.Username,
+			UserID:            admin.ID,
+			TwoFactorRequired: true,
+			PreAuthToken:      preAuthToken,
+		})
+		return
+	}
+
+	h.completeLogin(c, &admin, now)
+}
 
-	// 生成JWT令牌
-	expireDuration := time.Duration(h.config.JWT.ExpireHours) * time.Hour
-	token, err := utils.GenerateToken(admin.ID, admin.Username, expireDuration)
+// completeLogin 登录验证全部通过，清零失败次数并签发访问令牌
+func (h *AdminHandler) completeLogin(c *gin.Context, admin *models.SysAdmin, now time.Time) {
+	// 更新最后登录时间并清零失败次数
+	h.db.GetDB().Model(admin).Updates(map[string]interface{}{
+		"last_login":      now,
+		"failed_attempts": 0,
+		"last_failed_at":  nil,
+		"locked_until":    nil,
+	})
+	h.auditLogin(c, admin.ID, admin.Username, models.LoginResultSuccess)
+
+	// 创建新的登录会话
+	sessionID, err := utils.GenerateTokenID()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
 		return
 	}
-
-	// 返回响应
-	c.JSON(http.StatusOK, LoginResponse{
-		Token:    token,
-		Username: admin.Username,
-		UserID:   admin.ID,
-	})
+	h.issueSessionTokens(c, admin, sessionID)
 }
 
 // GetShortLinks 获取短链接列表
 func (h *AdminHandler) GetShortLinks(c *gin.Context) {
 	var links []models.DBShortLink
-	query := h.db.GetDB().Order("created_at DESC")
+	query := h.db.GetDB().Scopes(visibleLinks(c)).Order("created_at DESC")
 
 	// 分页参数
 	page := c.DefaultQuery("page", "1")
 	pageSize := c.DefaultQuery("pageSize", "10")
 
 	// 过滤参数
+	if domainID := c.Query("domainId"); domainID != "" {
+		query = query.Where("domain_id = ?", domainID)
+	}
+	if ownerID := c.Query("ownerId"); ownerID != "" {
+		query = query.Where("created_by = ?", ownerID)
+	}
+	if apiKeyID := c.Query("apiKeyId"); apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
 	if shortCode := c.Query("shortCode"); shortCode != "" {
 		query = query.Where("short_code LIKE ?", "%"+shortCode+"%")
 	}
@@ -101,10 +211,13 @@ func (h *AdminHandler) GetShortLinks(c *gin.Context) {
 		query = query.Where("original_url LIKE ?", "%"+originalURL+"%")
 	}
 	if status := c.Query("status"); status != "" {
-		if status == "active" {
-			query = query.Where("expires_at > ?", time.Now())
-		} else if status == "expired" {
+		switch status {
+		case "active":
+			query = query.Where("expires_at > ? AND status = ?", time.Now(), models.LinkStatusActive)
+		case "expired":
 			query = query.Where("expires_at <= ?", time.Now())
+		case models.LinkStatusDisabled, models.LinkStatusQuarantined:
+			query = query.Where("status = ?", status)
 		}
 	}
 
@@ -126,12 +239,8 @@ func (h *AdminHandler) GetShortLinks(c *gin.Context) {
 		return
 	}
 
-	// 格式化日期并构建完整短链接URL
-	formattedLinks := make([]models.FormattedShortLink, len(links))
-	accessBaseURL := h.config.Server.Access.BaseURL
-	for i, link := range links {
-		formattedLinks[i] = link.ToFormattedShortLink(accessBaseURL)
-	}
+	// 格式化日期并按所属域名构建完整短链接URL
+	formattedLinks := h.formatLinks(links)
 
 	// 返回响应
 	c.JSON(http.StatusOK, gin.H{
@@ -187,9 +296,18 @@ func (h *AdminHandler) GetHistoryLinks(c *gin.Context) {
 
 	// 查询历史表
 	var links []models.DBShortLink
-	query := h.db.GetDB().Table(historyTable).Order("created_at DESC")
+	query := h.db.GetDB().Table(historyTable).Scopes(visibleLinks(c)).Order("created_at DESC")
 
 	// 过滤参数
+	if domainID := c.Query("domainId"); domainID != "" {
+		query = query.Where("domain_id = ?", domainID)
+	}
+	if ownerID := c.Query("ownerId"); ownerID != "" {
+		query = query.Where("created_by = ?", ownerID)
+	}
+	if apiKeyID := c.Query("apiKeyId"); apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
 	if shortCode := c.Query("shortCode"); shortCode != "" {
 		query = query.Where("short_code LIKE ?", "%"+shortCode+"%")
 	}
@@ -219,12 +337,8 @@ func (h *AdminHandler) GetHistoryLinks(c *gin.Context) {
 		return
 	}
 
-	// 格式化日期并构建完整短链接URL
-	formattedLinks := make([]models.FormattedShortLink, len(links))
-	accessBaseURL := h.config.Server.Access.BaseURL
-	for i, link := range links {
-		formattedLinks[i] = link.ToFormattedShortLink(accessBaseURL)
-	}
+	// 格式化日期并按所属域名构建完整短链接URL
+	formattedLinks := h.formatLinks(links)
 
 	// 返回响应
 	c.JSON(http.StatusOK, gin.H{
@@ -247,7 +361,7 @@ func (h *AdminHandler) DeleteShortLink(c *gin.Context) {
 
 	// 查询短链接
 	var link models.DBShortLink
-	if err := h.db.GetDB().Where("id = ?", id).First(&link).Error; err != nil {
+	if err := h.db.GetDB().Scopes(ownedLinks(c)).Where("id = ?", id).First(&link).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
 		return
 	}
@@ -259,13 +373,16 @@ func (h *AdminHandler) DeleteShortLink(c *gin.Context) {
 	historyTable := h.config.Tasks.CleanExpiredLinks.HistoryTablePrefix + currentMonth
 
 	// 确保历史表存在
-	h.db.GetDB().Exec(`CREATE TABLE IF NOT EXISTS ` + historyTable + ` LIKE short_links`)
+	if err := models.EnsureHistoryTable(h.db.GetDB(), historyTable); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建历史表失败"})
+		return
+	}
 
 	// 开始事务
 	tx := h.db.GetDB().Begin()
 
 	// 将短链接插入历史表
-	if err := tx.Exec("INSERT INTO "+historyTable+" SELECT * FROM short_links WHERE id = ?", id).Error; err != nil {
+	if err := tx.Table(historyTable).Create(&link).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "移动短链接到历史表失败"})
 		return
@@ -284,13 +401,151 @@ func (h *AdminHandler) DeleteShortLink(c *gin.Context) {
 		return
 	}
 
-	// 从缓存中删除短链接
-	h.db.RemoveFromCache(link.ShortCode)
+	// 从缓存中删除短链接，并释放有效短链接配额
+	h.db.RemoveFromCache(link.DomainID, link.ShortCode)
+	h.quota.ReleaseActive(c.Request.Context(), &link)
 
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{"message": "短链接已成功删除"})
 }
 
+// UpdateShortLink 编辑短链接
+func (h *AdminHandler) UpdateShortLink(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的短链接ID"})
+		return
+	}
+
+	// 解析请求参数
+	var req models.UpdateShortLinkRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	// 校验并规范化目标URL，并按域名策略检查
+	rules := urlPolicy(&h.config.Server.Access.URLValidation)
+	var fields []FieldError
+	if req.Link != nil {
+		if fields = normalizeURLField(rules, "link", req.Link, fields); len(fields) == 0 {
+			fields = checkDomainPolicy(h.policy, "link", *req.Link, fields)
+		}
+	}
+	if req.OGImage != nil && *req.OGImage != "" {
+		fields = normalizeURLField(rules, "ogImage", req.OGImage, fields)
+	}
+	if req.DeviceRules != nil {
+		if deviceFields := normalizeDeviceRuleURLs(rules, *req.DeviceRules, nil); len(deviceFields) > 0 {
+			fields = append(fields, deviceFields...)
+		} else {
+			fields = checkDeviceRulePolicy(h.policy, *req.DeviceRules, fields)
+		}
+	}
+	if len(fields) > 0 {
+		respondFieldErrors(c, fields)
+		return
+	}
+
+	// 查询短链接
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(ownedLinks(c)).Where("id = ?", id).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	// 收集需要更新的字段
+	updates := make(map[string]interface{})
+	if req.Link != nil {
+		updates["original_url"] = *req.Link
+	}
+	if req.Expire != nil {
+		if *req.Expire <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "过期时间必须大于0"})
+			return
+		}
+		// 与创建时一致，从当前时间开始计算
+		updates["expires_at"] = time.Now().Add(time.Duration(*req.Expire) * time.Second)
+	}
+	if req.DeviceRules != nil {
+		if err := req.DeviceRules.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["device_rules"] = *req.DeviceRules
+	}
+	if req.UTMParams != nil {
+		updates["utm_params"] = *req.UTMParams
+	}
+	if req.QueryPassthrough != nil {
+		updates["query_passthrough"] = *req.QueryPassthrough
+	}
+	if req.PrefixMode != nil {
+		updates["prefix_mode"] = *req.PrefixMode
+	}
+	if req.RedirectType != nil {
+		if !models.IsValidRedirectType(*req.RedirectType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的跳转方式: " + *req.RedirectType})
+			return
+		}
+		updates["redirect_type"] = *req.RedirectType
+	}
+	if req.ForcePreview != nil {
+		updates["force_preview"] = *req.ForcePreview
+	}
+	if req.OGTitle != nil {
+		updates["og_title"] = *req.OGTitle
+	}
+	if req.OGDescription != nil {
+		updates["og_description"] = *req.OGDescription
+	}
+	if req.OGImage != nil {
+		updates["og_image"] = *req.OGImage
+	}
+	if req.ReportThreshold != nil {
+		updates["report_threshold"] = *req.ReportThreshold
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "没有需要更新的字段"})
+		return
+	}
+
+	// 修改有效期时按配额检查，已过期的短链接重新生效时占用一个有效短链接数，更新失败时归还
+	var reactivated []quota.Check
+	if req.Expire != nil {
+		checks, err := h.quota.LinkChecks(&link)
+		if err == nil {
+			err = h.quota.CheckTTL(checks, time.Duration(*req.Expire)*time.Second)
+		}
+		if err == nil && !link.ExpiresAt.After(time.Now()) {
+			if err = h.quota.ReserveActive(c.Request.Context(), checks); err == nil {
+				reactivated = checks
+			}
+		}
+		if err != nil {
+			respondQuotaError(c, "UpdateShortLink", err)
+			return
+		}
+	}
+
+	// 更新数据库
+	if err := h.db.GetDB().Model(&link).Updates(updates).Error; err != nil {
+		h.quota.CancelActive(c.Request.Context(), reactivated)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新短链接失败"})
+		return
+	}
+
+	// 从缓存中删除短链接，下次访问时重新加载
+	h.db.RemoveFromCache(link.DomainID, link.ShortCode)
+
+	// 返回更新后的短链接
+	if err := h.db.GetDB().Where("id = ?", id).First(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询短链接失败"})
+		return
+	}
+	c.JSON(http.StatusOK, h.formatLinks([]models.DBShortLink{link})[0])
+}
+
 // ChangePassword 修改密码
 func (h *AdminHandler) ChangePassword(c *gin.Context) {
 	// 获取当前登录用户ID
@@ -320,6 +575,11 @@ func (h *AdminHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	if req.NewPassword == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "新密码不能与当前密码相同"})
+		return
+	}
+
 	// 生成新密码的哈希
 	hashedPassword, err := models.HashPassword(req.NewPassword)
 	if err != nil {
@@ -327,12 +587,22 @@ func (h *AdminHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// 更新密码
-	if err := h.db.GetDB().Model(&admin).Update("password", hashedPassword).Error; err != nil {
+	// 更新密码，并解除首次登录必须修改密码的限制
+	if err := h.db.GetDB().Model(&admin).Updates(map[string]interface{}{
+		"password":             hashedPassword,
+		"must_change_password": false,
+	}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新密码失败"})
 		return
 	}
 
+	// 吊销该管理员的所有登录会话，需要使用新密码重新登录
+	if err := h.revokeAdminSessions(c, admin.ID); err != nil {
+		logrus.Errorf("revoke admin %d sessions error: %v", admin.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "密码已修改，但注销已登录的会话失败，请稍后重试"})
+		return
+	}
+
 	// 返回成功响应
-	c.JSON(http.StatusOK, gin.H{"message": "密码修改成功"})
+	c.JSON(http.StatusOK, gin.H{"message": "密码修改成功，请重新登录"})
 }
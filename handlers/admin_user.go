@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminAccountResponse 创建或重置密码后的管理员账户，InitialPassword只在本次响应中返回
+type AdminAccountResponse struct {
+	AdminInfo
+	InitialPassword string `json:"initialPassword,omitempty"`
+}
+
+// managedAdmin 查询路径参数指定的管理员，并检查当前用户能否管理该账户
+// 不能通过管理接口修改或删除自己的账户，防止误操作导致失去管理权限
+func (h *AdminHandler) managedAdmin(c *gin.Context) (*models.SysAdmin, bool) {
+	var admin models.SysAdmin
+	if err := h.db.GetDB().Scopes(adminScope(c)).Where("id = ?", c.Param("id")).First(&admin).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "管理员不存在"})
+		return nil, false
+	}
+	if userID, _ := c.Get("userID"); userID == admin.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不能修改或删除自己的账户"})
+		return nil, false
+	}
+	if !models.CanManageRole(c.GetString("role"), admin.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权管理该角色的账户"})
+		return nil, false
+	}
+	return &admin, true
+}
+
+// CreateAdmin 创建管理员账户，只能创建不高于自己可管理范围的角色
+// 未提供密码时生成随机初始密码；新账户首次登录后必须修改密码
+func (h *AdminHandler) CreateAdmin(c *gin.Context) {
+	var req models.CreateAdminRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !models.CanManageRole(c.GetString("role"), req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权创建该角色的账户"})
+		return
+	}
+
+	// 默认在当前工作区创建，平台所有者可以在其他工作区创建账户
+	workspaceID := c.GetInt64("workspaceID")
+	if req.WorkspaceID != nil && *req.WorkspaceID != workspaceID {
+		if !isPlatformOwner(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "无权在其他工作区创建账户"})
+			return
+		}
+		if !models.WorkspaceExists(h.db.GetDB(), *req.WorkspaceID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "工作区不存在"})
+			return
+		}
+		workspaceID = *req.WorkspaceID
+	}
+
+	var count int64
+	h.db.GetDB().Model(&models.SysAdmin{}).Where("username = ?", req.Username).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "用户名已存在"})
+		return
+	}
+
+	password := req.Password
+	initialPassword := ""
+	if password == "" {
+		generated, err := models.GenerateRandomPassword()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成初始密码失败"})
+			return
+		}
+		password = generated
+		initialPassword = generated
+	}
+	hashedPassword, err := models.HashPassword(password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "密码加密失败"})
+		return
+	}
+
+	now := time.Now()
+	admin := &models.SysAdmin{
+		Username:           req.Username,
+		Password:           hashedPassword,
+		Role:               req.Role,
+		WorkspaceID:        workspaceID,
+		LastLogin:          now,
+		MustChangePassword: true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := h.db.GetDB().Create(admin).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建管理员失败"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("admin %s (role: %s) created by %v", admin.Username, admin.Role, username)
+	c.JSON(http.StatusOK, AdminAccountResponse{
+		AdminInfo:       newAdminInfo(admin),
+		InitialPassword: initialPassword,
+	})
+}
+
+// UpdateAdmin 修改管理员角色或重置密码，修改后该账户的所有登录会话失效
+func (h *AdminHandler) UpdateAdmin(c *gin.Context) {
+	admin, ok := h.managedAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAdminRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Role != nil && *req.Role != admin.Role {
+		if !models.CanManageRole(c.GetString("role"), *req.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "无权设置该角色"})
+			return
+		}
+		updates["role"] = *req.Role
+		admin.Role = *req.Role
+	}
+
+	initialPassword := ""
+	if req.ResetPassword {
+		generated, err := models.GenerateRandomPassword()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成初始密码失败"})
+			return
+		}
+		hashedPassword, err := models.HashPassword(generated)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "密码加密失败"})
+			return
+		}
+		initialPassword = generated
+		updates["password"] = hashedPassword
+		updates["must_change_password"] = true
+		admin.MustChangePassword = true
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, AdminAccountResponse{AdminInfo: newAdminInfo(admin)})
+		return
+	}
+
+	if err := h.db.GetDB().Model(admin).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新管理员失败"})
+		return
+	}
+
+	// 访问令牌中带有角色，角色或密码变更后需要重新登录
+	if err := h.revokeAdminSessions(c, admin.ID); err != nil {
+		logrus.Errorf("revoke admin %d sessions error: %v", admin.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "管理员已更新，但注销其已登录的会话失败，请稍后重试"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("admin %s updated by %v, role: %s, password reset: %v", admin.Username, username, admin.Role, req.ResetPassword)
+	c.JSON(http.StatusOK, AdminAccountResponse{
+		AdminInfo:       newAdminInfo(admin),
+		InitialPassword: initialPassword,
+	})
+}
+
+// DeleteAdmin 删除管理员账户，同时删除恢复码并吊销所有登录会话，登录审计记录保留
+func (h *AdminHandler) DeleteAdmin(c *gin.Context) {
+	admin, ok := h.managedAdmin(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.GetDB().Delete(admin).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除管理员失败"})
+		return
+	}
+	if err := models.DeleteRecoveryCodes(h.db.GetDB(), admin.ID); err != nil {
+		logrus.Errorf("delete recovery codes error: %v", err)
+	}
+	if err := h.revokeAdminSessions(c, admin.ID); err != nil {
+		logrus.Errorf("revoke admin %d sessions error: %v", admin.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "管理员已删除，但注销其已登录的会话失败，请稍后重试"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("admin %s (role: %s) deleted by %v", admin.Username, admin.Role, username)
+	c.JSON(http.StatusOK, gin.H{"message": "管理员已删除"})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+)
+
+// GetAPIKeys 获取API密钥列表，不返回密钥明文
+func (h *AdminHandler) GetAPIKeys(c *gin.Context) {
+	var keys []models.APIKey
+	if err := h.db.GetDB().Scopes(inWorkspace(c)).Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询API密钥失败"})
+		return
+	}
+
+	items := make([]models.APIKeyResponse, len(keys))
+	for i := range keys {
+		items[i] = keys[i].ToResponse()
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": items})
+}
+
+// CreateAPIKey 签发API密钥，密钥明文只在本次响应中返回
+func (h *AdminHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	key, record, err := models.GenerateAPIKey(req.Name, req.Scopes, time.Duration(req.Expire)*time.Second)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成API密钥失败"})
+		return
+	}
+	username, _ := c.Get("username")
+	record.CreatedBy = fmt.Sprint(username)
+	record.WorkspaceID = c.GetInt64("workspaceID")
+
+	if err := h.db.GetDB().Create(record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存API密钥失败"})
+		return
+	}
+	logrus.Infof("api key %s (id: %d) issued by %v", record.Prefix, record.ID, username)
+
+	resp := record.ToResponse()
+	resp.Key = key
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeAPIKey 吊销API密钥，吊销后立即失效，记录保留用于审计
+func (h *AdminHandler) RevokeAPIKey(c *gin.Context) {
+	var key models.APIKey
+	if err := h.db.GetDB().Scopes(inWorkspace(c)).Where("id = ?", c.Param("id")).First(&key).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API密钥不存在"})
+		return
+	}
+	if key.RevokedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "API密钥已吊销"})
+		return
+	}
+
+	if err := h.db.GetDB().Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "吊销API密钥失败"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("api key %s (id: %d) revoked by %v", key.Prefix, key.ID, username)
+	c.JSON(http.StatusOK, gin.H{"message": "API密钥已吊销"})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/templates"
+	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// resolveDeviceTarget 根据访问者的设备平台计算跳转目标
+// defaultURL 为未匹配规则或规则未指定url时使用的地址，
+// 返回匹配的规则（可能为nil）以及网页跳转地址
+func resolveDeviceTarget(link *models.ShortLink, platform, defaultURL string) (*models.DeviceRule, string) {
+	rule, ok := link.DeviceRules.Match(platform)
+	if !ok {
+		return nil, defaultURL
+	}
+
+	if rule.URL != "" {
+		return rule, rule.URL
+	}
+	return rule, defaultURL
+}
+
+// hasDeepLink 规则是否带有可以使用的深度链接
+// 深度链接在保存时已校验，这里再次检查协议，避免校验加入前保存的危险链接在唤起页面中执行，不可用时直接跳转到网页地址
+func (h *ShortLinkHandler) hasDeepLink(rule *models.DeviceRule) bool {
+	if rule == nil || rule.DeepLink == "" {
+		return false
+	}
+	if err := utils.ValidateDeepLink(rule.DeepLink, h.config.URLValidation.DeepLinkSchemes); err != nil {
+		logrus.Warnf("skip deep link %q: %v", rule.DeepLink, err)
+		return false
+	}
+	return true
+}
+
+// renderDeepLinkPage 渲染唤起App的回退页面
+func (h *ShortLinkHandler) renderDeepLinkPage(c *gin.Context, rule *models.DeviceRule, fallbackURL string) {
+	timeout := rule.FallbackTimeout
+	if timeout <= 0 {
+		timeout = models.DefaultDeepLinkTimeout
+	}
+
+	c.Header("Cache-Control", "no-store")
+	h.renderPage(c, http.StatusOK, templates.PageDeepLink, gin.H{
+		"deepLink":    rule.DeepLink,
+		"fallbackURL": fallbackURL,
+		"timeout":     timeout,
+	})
+}
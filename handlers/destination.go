@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// appendPathSuffix 将短码之后的路径追加到目标URL的路径末尾
+func appendPathSuffix(link *models.ShortLink, targetURL, suffix string) string {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		logrus.Errorf("append path suffix for %s error: %v", link.ShortCode, err)
+		return targetURL
+	}
+	return u.JoinPath(suffix).String()
+}
+
+// isSafePathSuffix 检查路径后缀是否不含"."和".."路径段
+// 路径参数已经过URL解码，%2e%2e同样会被识别为".."
+func isSafePathSuffix(suffix string) bool {
+	for _, segment := range strings.Split(suffix, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// applyQueryParams 将UTM参数和透传的请求参数合并到目标URL
+// 参数冲突时的优先级：目标URL自带的参数 > 短链接配置的UTM参数 > 访问请求的查询参数
+func applyQueryParams(link *models.ShortLink, targetURL string, incoming url.Values) string {
+	sets := []url.Values{link.UTMParams.Values()}
+	if link.QueryPassthrough {
+		sets = append(sets, incoming)
+	}
+
+	merged, err := utils.MergeQuery(targetURL, sets...)
+	if err != nil {
+		logrus.Errorf("merge query params for %s error: %v", link.ShortCode, err)
+		return targetURL
+	}
+	return merged
+}
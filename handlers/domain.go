@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+)
+
+// defaultDomain 返回配置文件中的默认域名
+func (h *ShortLinkHandler) defaultDomain() *models.Domain {
+	return &models.Domain{
+		ID:          models.DefaultDomainID,
+		BaseURL:     h.baseURL,
+		FallbackURL: h.config.FallbackURL,
+	}
+}
+
+// requestDomain 根据请求的Host解析访问的域名，未登记的Host使用默认域名
+func (h *ShortLinkHandler) requestDomain(c *gin.Context) *models.Domain {
+	if domain, ok := h.store.ResolveDomain(c.Request.Host); ok {
+		return domain
+	}
+	return h.defaultDomain()
+}
+
+// notFound 短码不存在时跳转到域名的兜底地址，未设置时返回404页面
+func (h *ShortLinkHandler) notFound(c *gin.Context, domain *models.Domain) {
+	if domain.FallbackURL != "" {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, domain.FallbackURL)
+		return
+	}
+	h.renderNotFound(c)
+}
+
+// RedirectDomainRoot 访问域名根路径时跳转到兜底地址
+func (h *ShortLinkHandler) RedirectDomainRoot(c *gin.Context) {
+	h.notFound(c, h.requestDomain(c))
+}
+
+// linkBaseURL 返回短链接所属域名的BaseURL
+func (h *AdminHandler) linkBaseURL(domainID int64, baseURLs map[int64]string) string {
+	if baseURL, ok := baseURLs[domainID]; ok {
+		return baseURL
+	}
+	return h.config.Server.Access.BaseURL
+}
+
+// formatLinks 格式化短链接列表，按所属域名构建完整短链接URL
+func (h *AdminHandler) formatLinks(links []models.DBShortLink) []models.FormattedShortLink {
+	baseURLs := h.db.Domains().BaseURLs()
+	pathPrefix := h.config.Server.Access.ShortPathPrefix()
+	formattedLinks := make([]models.FormattedShortLink, len(links))
+	for i := range links {
+		formattedLinks[i] = links[i].ToFormattedShortLink(h.linkBaseURL(links[i].DomainID, baseURLs), pathPrefix)
+	}
+	return formattedLinks
+}
+
+// GetDomains 获取域名列表
+func (h *AdminHandler) GetDomains(c *gin.Context) {
+	var domains []models.Domain
+	if err := h.db.GetDB().Scopes(inWorkspace(c)).Order("created_at ASC").Find(&domains).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询域名失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"default": gin.H{
+			"id":          models.DefaultDomainID,
+			"baseUrl":     h.config.Server.Access.BaseURL,
+			"fallbackUrl": h.config.Server.Access.FallbackURL,
+		},
+		"domains": domains,
+	})
+}
+
+// CreateDomain 添加域名
+func (h *AdminHandler) CreateDomain(c *gin.Context) {
+	var req models.DomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	host := models.NormalizeHost(req.Host)
+	var count int64
+	h.db.GetDB().Model(&models.Domain{}).Where("host = ?", host).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "域名已存在: " + host})
+		return
+	}
+
+	domain := models.Domain{
+		Host:        host,
+		BaseURL:     req.BaseURL,
+		FallbackURL: req.FallbackURL,
+		WorkspaceID: c.GetInt64("workspaceID"),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := h.db.GetDB().Create(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加域名失败"})
+		return
+	}
+	h.db.Domains().Reload()
+
+	c.JSON(http.StatusOK, domain)
+}
+
+// UpdateDomain 编辑域名
+func (h *AdminHandler) UpdateDomain(c *gin.Context) {
+	var req models.DomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	var domain models.Domain
+	if err := h.db.GetDB().Scopes(inWorkspace(c)).Where("id = ?", c.Param("id")).First(&domain).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "域名不存在"})
+		return
+	}
+
+	host := models.NormalizeHost(req.Host)
+	var count int64
+	h.db.GetDB().Model(&models.Domain{}).Where("host = ? AND id <> ?", host, domain.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "域名已存在: " + host})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"host":         host,
+		"base_url":     req.BaseURL,
+		"fallback_url": req.FallbackURL,
+		"updated_at":   time.Now(),
+	}
+	if err := h.db.GetDB().Model(&domain).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新域名失败"})
+		return
+	}
+	h.db.Domains().Reload()
+
+	// 返回更新后的域名
+	if err := h.db.GetDB().Where("id = ?", domain.ID).First(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询域名失败"})
+		return
+	}
+	c.JSON(http.StatusOK, domain)
+}
+
+// DeleteDomain 删除域名，域名下仍有短链接时不允许删除
+func (h *AdminHandler) DeleteDomain(c *gin.Context) {
+	var domain models.Domain
+	if err := h.db.GetDB().Scopes(inWorkspace(c)).Where("id = ?", c.Param("id")).First(&domain).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "域名不存在"})
+		return
+	}
+
+	var count int64
+	h.db.GetDB().Model(&models.DBShortLink{}).Where("domain_id = ?", domain.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "域名下仍有短链接，无法删除"})
+		return
+	}
+
+	if err := h.db.GetDB().Delete(&domain).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除域名失败"})
+		return
+	}
+	h.db.Domains().Reload()
+
+	c.JSON(http.StatusOK, gin.H{"message": "域名已成功删除"})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateLinkStatus 修改短链接状态（启用、停用或隔离），不会删除短链接
+func (h *AdminHandler) UpdateLinkStatus(c *gin.Context) {
+	var req models.UpdateLinkStatusRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(ownedLinks(c)).Where("id = ?", c.Param("id")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	// 恢复为正常状态时清空原因
+	reason := req.Reason
+	if req.Status == models.LinkStatusActive {
+		reason = ""
+	}
+
+	updates := map[string]interface{}{
+		"status":            req.Status,
+		"status_reason":     reason,
+		"status_updated_at": time.Now(),
+	}
+	if err := h.db.GetDB().Model(&link).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "修改短链接状态失败"})
+		return
+	}
+
+	// 从缓存中删除短链接，下次访问时重新加载
+	h.db.RemoveFromCache(link.DomainID, link.ShortCode)
+
+	username, _ := c.Get("username")
+	logrus.Infof("short link %s (id: %d) status changed to %s by %v: %s", link.ShortCode, link.ID, req.Status, username, reason)
+
+	// 返回更新后的短链接
+	if err := h.db.GetDB().Where("id = ?", link.ID).First(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询短链接失败"})
+		return
+	}
+	c.JSON(http.StatusOK, h.formatLinks([]models.DBShortLink{link})[0])
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// AdminInfo 管理员账户信息
+type AdminInfo struct {
+	ID                 int64      `json:"id"`
+	Username           string     `json:"username"`
+	WorkspaceID        int64      `json:"workspaceId"`
+	Role               string     `json:"role"`
+	TwoFactorEnabled   bool       `json:"twoFactorEnabled"`
+	LastLogin          time.Time  `json:"lastLogin"`
+	FailedAttempts     int        `json:"failedAttempts"`
+	LockedUntil        *time.Time `json:"lockedUntil"`
+	MustChangePassword bool       `json:"mustChangePassword"`
+	CreatedAt          time.Time  `json:"createdAt"`
+}
+
+// newAdminInfo 将管理员账户转换为不含密码和密钥的账户信息
+func newAdminInfo(admin *models.SysAdmin) AdminInfo {
+	return AdminInfo{
+		ID:                 admin.ID,
+		Username:           admin.Username,
+		WorkspaceID:        admin.WorkspaceID,
+		Role:               admin.Role,
+		TwoFactorEnabled:   admin.TOTPEnabled,
+		LastLogin:          admin.LastLogin,
+		FailedAttempts:     admin.FailedAttempts,
+		LockedUntil:        admin.LockedUntil,
+		MustChangePassword: admin.MustChangePassword,
+		CreatedAt:          admin.CreatedAt,
+	}
+}
+
+// loginDelay 连续失败次数达到阈值后，每次失败需要等待的时间翻倍，返回还需等待的时长
+func loginDelay(admin *models.SysAdmin, config conf.LoginSecurityConfig, now time.Time) time.Duration {
+	if admin.FailedAttempts < config.DelayAfter || admin.LastFailedAt == nil {
+		return 0
+	}
+
+	delay := time.Duration(config.MaxDelaySeconds) * time.Second
+	if shift := admin.FailedAttempts - config.DelayAfter; shift < 16 {
+		if d := time.Second << shift; d < delay {
+			delay = d
+		}
+	}
+	return admin.LastFailedAt.Add(delay).Sub(now)
+}
+
+// checkLoginIP 检查来源IP的登录失败次数，超出限制时返回错误响应
+func (h *AdminHandler) checkLoginIP(c *gin.Context, username string, config conf.LoginSecurityConfig, now time.Time) bool {
+	window := time.Duration(config.IPWindowMinutes) * time.Minute
+	if models.CountIPLoginFailures(h.db.GetDB(), c.ClientIP(), now.Add(-window)) >= int64(config.IPMaxAttempts) {
+		h.auditLogin(c, 0, username, models.LoginResultIPBlocked)
+		setRetryAfter(c, window)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "登录失败次数过多，请稍后再试"})
+		return false
+	}
+	return true
+}
+
+// dummyPasswordHash 用户名不存在时用于校验密码的哈希，使响应耗时与密码错误时一致
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := models.HashPassword("go-short-link")
+	return hash
+})
+
+// respondLoginFailed 返回登录失败响应
+// 用户名不存在、账户锁定、等待时间未到和密码错误的响应相同，只在登录审计中区分，避免借此判断账户是否存在
+func respondLoginFailed(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
+}
+
+// accountBlocked 检查账户锁定和失败等待时间，不允许登录时返回审计结果和还需等待的时长
+func accountBlocked(admin *models.SysAdmin, config conf.LoginSecurityConfig, now time.Time) (string, time.Duration) {
+	if admin.IsLocked(now) {
+		return models.LoginResultLocked, admin.LockedUntil.Sub(now)
+	}
+	if wait := loginDelay(admin, config, now); wait > 0 {
+		return models.LoginResultThrottled, wait
+	}
+	return "", 0
+}
+
+// checkLoginAccount 提交两步验证码时检查账户锁定和失败等待时间，不允许登录时返回错误响应
+// 此时密码已经验证通过，可以告知具体原因
+func (h *AdminHandler) checkLoginAccount(c *gin.Context, admin *models.SysAdmin, config conf.LoginSecurityConfig, now time.Time) bool {
+	result, wait := accountBlocked(admin, config, now)
+	if result == "" {
+		return true
+	}
+
+	h.auditLogin(c, admin.ID, admin.Username, result)
+	setRetryAfter(c, wait)
+	if result == models.LoginResultLocked {
+		c.JSON(http.StatusLocked, gin.H{"error": "账户已被临时锁定，请稍后再试或联系管理员解锁"})
+	} else {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("登录失败次数过多，请%d秒后再试", ceilSeconds(wait))})
+	}
+	return false
+}
+
+// recordLoginFailure 累加连续失败次数，达到上限时锁定账户并清零计数，返回本次失败的审计结果
+func (h *AdminHandler) recordLoginFailure(admin *models.SysAdmin, config conf.LoginSecurityConfig, now time.Time, result string) string {
+	if admin.FailedAttempts+1 >= config.MaxAttempts {
+		lockedUntil := now.Add(time.Duration(config.LockoutMinutes) * time.Minute)
+		h.db.GetDB().Model(admin).Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"last_failed_at":  now,
+			"locked_until":    lockedUntil,
+		})
+		logrus.Warnf("admin %s locked until %s after %d failed logins", admin.Username, lockedUntil.Format(time.RFC3339), config.MaxAttempts)
+		return models.LoginResultAccountLocked
+	}
+
+	h.db.GetDB().Model(admin).Updates(map[string]interface{}{
+		"failed_attempts": gorm.Expr("failed_attempts + 1"),
+		"last_failed_at":  now,
+	})
+	return result
+}
+
+// auditLogin 记录登录审计
+func (h *AdminHandler) auditLogin(c *gin.Context, adminID int64, username, result string) {
+	audit := models.LoginAudit{
+		AdminID:   adminID,
+		Username:  username,
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+		Result:    result,
+		Success:   result == models.LoginResultSuccess,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.GetDB().Create(&audit).Error; err != nil {
+		logrus.Errorf("save login audit error: %v", err)
+	}
+	if !audit.Success {
+		logrus.Warnf("login failed, username: %s, ip: %s, result: %s", username, audit.IP, result)
+	}
+}
+
+// truncate 按字节截断字符串，避免超出字段长度
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// setRetryAfter 设置Retry-After响应头
+func setRetryAfter(c *gin.Context, d time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(ceilSeconds(d)))
+}
+
+// ceilSeconds 将时长向上取整为秒，至少为1秒
+func ceilSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// GetAdmins 获取管理员列表，包含登录失败和锁定状态
+func (h *AdminHandler) GetAdmins(c *gin.Context) {
+	var admins []models.SysAdmin
+	if err := h.db.GetDB().Scopes(adminScope(c)).Order("created_at ASC").Find(&admins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询管理员失败"})
+		return
+	}
+
+	items := make([]AdminInfo, len(admins))
+	for i := range admins {
+		items[i] = newAdminInfo(&admins[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"admins": items})
+}
+
+// UnlockAdmin 解除管理员账户锁定并清零失败次数
+func (h *AdminHandler) UnlockAdmin(c *gin.Context) {
+	var admin models.SysAdmin
+	if err := h.db.GetDB().Scopes(adminScope(c)).Where("id = ?", c.Param("id")).First(&admin).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "管理员不存在"})
+		return
+	}
+	if !models.CanManageRole(c.GetString("role"), admin.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权管理该角色的账户"})
+		return
+	}
+
+	if err := h.db.GetDB().Model(&admin).Updates(map[string]interface{}{
+		"failed_attempts": 0,
+		"last_failed_at":  nil,
+		"locked_until":    nil,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "解锁失败"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("admin %s unlocked by %v", admin.Username, username)
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("账户 %s 已解锁", admin.Username)})
+}
+
+// GetLoginAudits 获取登录审计记录
+func (h *AdminHandler) GetLoginAudits(c *gin.Context) {
+	query := h.db.GetDB().Order("created_at DESC")
+
+	// 平台所有者可以查看所有记录，其他用户只能查看本工作区账户的记录
+	if !isPlatformOwner(c) {
+		admins := h.db.GetDB().Model(&models.SysAdmin{}).Select("id").Scopes(inWorkspace(c))
+		query = query.Where("admin_id IN (?)", admins)
+	}
+
+	// 分页参数
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+
+	// 过滤参数
+	if username := c.Query("username"); username != "" {
+		query = query.Where("username = ?", username)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if success := c.Query("success"); success != "" {
+		query = query.Where("success = ?", success == "true")
+	}
+
+	var total int64
+	if err := query.Model(&models.LoginAudit{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "计数查询失败: " + err.Error()})
+		return
+	}
+
+	var audits []models.LoginAudit
+	if err := query.Scopes(models.Paginate(page, pageSize)).Find(&audits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询数据失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":  total,
+		"audits": audits,
+	})
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// renderPage 按访问者的语言和访问域名渲染页面
+func (h *ShortLinkHandler) renderPage(c *gin.Context, status int, page string, data gin.H) {
+	lang := h.pages.MatchLanguage(c.GetHeader("Accept-Language"))
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Content-Language", lang)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.Status(status)
+
+	if err := h.pages.Render(c.Writer, c.Request.Host, lang, page, data); err != nil {
+		logrus.Errorf("render page %s error: %v", page, err)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/sirupsen/logrus"
+)
+
+// GetDomainPolicies 获取管理员维护的域名策略列表
+func (h *AdminHandler) GetDomainPolicies(c *gin.Context) {
+	query := h.db.GetDB().Order("created_at DESC")
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if pattern := c.Query("pattern"); pattern != "" {
+		query = query.Where("pattern LIKE ?", "%"+pattern+"%")
+	}
+
+	var policies []models.DomainPolicy
+	if err := query.Find(&policies).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询域名策略失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"policies": policies})
+}
+
+// CreateDomainPolicy 添加域名策略
+func (h *AdminHandler) CreateDomainPolicy(c *gin.Context) {
+	var req models.DomainPolicyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pattern := policy.NormalizePattern(req.Pattern)
+	var count int64
+	h.db.GetDB().Model(&models.DomainPolicy{}).Where("pattern = ?", pattern).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "域名策略已存在: " + pattern})
+		return
+	}
+
+	username, _ := c.Get("username")
+	item := models.DomainPolicy{
+		Pattern:   pattern,
+		Action:    req.Action,
+		Reason:    req.Reason,
+		CreatedBy: fmt.Sprint(username),
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.GetDB().Create(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加域名策略失败"})
+		return
+	}
+	if err := h.policy.Reload(); err != nil {
+		logrus.Errorf("reload domain policy error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "域名策略已保存，但刷新失败，将在下次定期刷新时生效"})
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteDomainPolicy 删除域名策略
+func (h *AdminHandler) DeleteDomainPolicy(c *gin.Context) {
+	var item models.DomainPolicy
+	if err := h.db.GetDB().Where("id = ?", c.Param("id")).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "域名策略不存在"})
+		return
+	}
+
+	if err := h.db.GetDB().Delete(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除域名策略失败"})
+		return
+	}
+	if err := h.policy.Reload(); err != nil {
+		logrus.Errorf("reload domain policy error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "域名策略已删除，但刷新失败，将在下次定期刷新时生效"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "域名策略已成功删除"})
+}
+
+// CheckDomainPolicy 检查目标URL是否被域名策略允许
+func (h *AdminHandler) CheckDomainPolicy(c *gin.Context) {
+	target := c.Query("url")
+	if target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url不能为空"})
+		return
+	}
+
+	decision := h.policy.CheckURL(target)
+	c.JSON(http.StatusOK, gin.H{
+		"allowed": decision.Allowed,
+		"reason":  decision.Reason,
+	})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/templates"
+)
+
+// renderSocialCard 渲染带有Open Graph和Twitter Card元数据的页面
+func (h *ShortLinkHandler) renderSocialCard(c *gin.Context, link *models.ShortLink, shortURL, targetURL string) {
+	card := "summary"
+	if link.OGImage != "" {
+		card = "summary_large_image"
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	h.renderPage(c, http.StatusOK, templates.PageOpenGraph, gin.H{
+		"title":       link.OGTitle,
+		"description": link.OGDescription,
+		"image":       link.OGImage,
+		"card":        card,
+		"shortURL":    shortURL,
+		"url":         targetURL,
+	})
+}
+
+// renderPreview 渲染短链接预览页，展示目标地址供访问者确认，continueURL为"继续访问"按钮的地址
+func (h *ShortLinkHandler) renderPreview(c *gin.Context, link *models.ShortLink, targetURL, continueURL string) {
+	domain := ""
+	if u, err := url.Parse(targetURL); err == nil {
+		domain = u.Hostname()
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	h.renderPage(c, http.StatusOK, templates.PagePreview, gin.H{
+		"url":         targetURL,
+		"continueURL": continueURL,
+		"domain":      domain,
+		"createdAt":   models.FormatTime(link.CreatedAt),
+		"expiresAt":   models.FormatTime(link.ExpiresAt),
+	})
+}
+
+// continueURL 返回强制预览页中确认继续访问的地址，即带有continue=1参数的当前短链接
+func continueURL(c *gin.Context, query url.Values) string {
+	values := url.Values{}
+	for key, value := range query {
+		values[key] = value
+	}
+	values.Set("continue", "1")
+	return (&url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}).String()
+}
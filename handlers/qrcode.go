@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	_ "image/jpeg" // 注册JPEG解码器，用于加载Logo
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/utils"
+)
+
+// 二维码默认参数
+const (
+	defaultQRSize    = 256
+	defaultQRMaxSize = 2048
+	defaultQRMargin  = 4
+	maxQRMargin      = 32
+)
+
+// parseQROptions 从查询参数解析二维码生成选项
+// 支持的参数: format(png/svg)、size、level(L/M/Q/H)、margin、fg、bg、logo
+func parseQROptions(c *gin.Context, config *conf.QRCodeConfig) (utils.QROptions, error) {
+	opts := utils.QROptions{
+		Format:     c.DefaultQuery("format", utils.QRFormatPNG),
+		Size:       config.DefaultSize,
+		Level:      c.Query("level"),
+		Margin:     defaultQRMargin,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+	if opts.Size <= 0 {
+		opts.Size = defaultQRSize
+	}
+
+	if opts.Format != utils.QRFormatPNG && opts.Format != utils.QRFormatSVG {
+		return opts, errors.New("不支持的图片格式: " + opts.Format)
+	}
+
+	if size := c.Query("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		maxSize := config.MaxSize
+		if maxSize <= 0 {
+			maxSize = defaultQRMaxSize
+		}
+		if err != nil || value <= 0 || value > maxSize {
+			return opts, errors.New("无效的图片尺寸")
+		}
+		opts.Size = value
+	}
+
+	if margin := c.Query("margin"); margin != "" {
+		value, err := strconv.Atoi(margin)
+		if err != nil || value < 0 || value > maxQRMargin {
+			return opts, errors.New("无效的留白大小")
+		}
+		opts.Margin = value
+	}
+
+	if _, err := utils.ParseRecoveryLevel(opts.Level); err != nil {
+		return opts, err
+	}
+
+	if fg := c.Query("fg"); fg != "" {
+		fgColor, err := utils.ParseHexColor(fg)
+		if err != nil {
+			return opts, err
+		}
+		opts.Foreground = fgColor
+	}
+	if bg := c.Query("bg"); bg != "" {
+		bgColor, err := utils.ParseHexColor(bg)
+		if err != nil {
+			return opts, err
+		}
+		opts.Background = bgColor
+	}
+
+	if name := c.Query("logo"); name != "" {
+		logo, err := loadQRLogo(config.LogoDir, name)
+		if err != nil {
+			return opts, err
+		}
+		opts.Logo = logo
+	}
+
+	return opts, nil
+}
+
+// loadQRLogo 从Logo目录加载指定的图片，只允许访问目录下的文件
+func loadQRLogo(dir, name string) (image.Image, error) {
+	if dir == "" {
+		return nil, errors.New("未配置Logo目录")
+	}
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, errors.New("无效的Logo名称")
+	}
+
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, errors.New("Logo不存在: " + name)
+	}
+	defer file.Close()
+
+	logo, _, err := image.Decode(file)
+	if err != nil {
+		return nil, errors.New("无法解析Logo图片: " + name)
+	}
+	return logo, nil
+}
+
+// writeQRCode 生成并返回短链接的二维码图片
+func writeQRCode(c *gin.Context, config *conf.QRCodeConfig, shortLink string) {
+	opts, err := parseQROptions(c, config)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, contentType, err := utils.RenderQRCode(shortLink, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成二维码失败"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GetQRCode 获取短链接的二维码
+func (h *AdminHandler) GetQRCode(c *gin.Context) {
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(visibleLinks(c)).Where("id = ?", c.Param("id")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	baseURL := h.linkBaseURL(link.DomainID, h.db.Domains().BaseURLs())
+	writeQRCode(c, &h.config.Server.Access.QRCode, utils.BuildShortLink(baseURL, h.config.Server.Access.ShortPathPrefix(), link.ShortCode))
+}
+
+// serveQRCode 在访问服务上返回短链接的二维码
+func (h *ShortLinkHandler) serveQRCode(c *gin.Context, domain *models.Domain, shortCode string) {
+	if _, err := h.store.Get(domain.ID, shortCode); err != nil {
+		h.renderNotFound(c)
+		return
+	}
+
+	writeQRCode(c, &h.config.QRCode, h.buildShortLink(domain, shortCode))
+}
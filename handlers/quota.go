@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/quota"
+)
+
+// GetQuotaUsage 获取工作区及其成员、API密钥的配额使用情况
+// 默认查询当前工作区，平台所有者可以通过workspaceId参数查询其他工作区
+func (h *AdminHandler) GetQuotaUsage(c *gin.Context) {
+	workspaceID := c.GetInt64("workspaceID")
+	if value := c.Query("workspaceId"); value != "" && isPlatformOwner(c) {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || !models.WorkspaceExists(h.db.GetDB(), id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "工作区不存在"})
+			return
+		}
+		workspaceID = id
+	}
+
+	ctx := c.Request.Context()
+	limits, err := h.quota.WorkspaceLimits(workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询工作区配额失败"})
+		return
+	}
+	workspaceUsage, err := h.quota.Usage(ctx, quota.Subject{Kind: quota.KindWorkspace, ID: workspaceID}, limits)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询配额使用情况失败"})
+		return
+	}
+
+	scope := models.InWorkspace(workspaceID)
+	var admins []models.SysAdmin
+	if err := h.db.GetDB().Scopes(scope).Order("id ASC").Find(&admins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询管理员失败"})
+		return
+	}
+	var keys []models.APIKey
+	if err := h.db.GetDB().Scopes(scope).Where("revoked_at IS NULL").Order("id ASC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询API密钥失败"})
+		return
+	}
+
+	userLimits := h.quota.UserLimits()
+	users := make([]quota.Usage, 0, len(admins))
+	for _, admin := range admins {
+		usage, err := h.quota.Usage(ctx, quota.Subject{Kind: quota.KindUser, ID: admin.ID}, userLimits)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询配额使用情况失败"})
+			return
+		}
+		usage.Name = admin.Username
+		users = append(users, usage)
+	}
+	apiKeys := make([]quota.Usage, 0, len(keys))
+	for _, key := range keys {
+		usage, err := h.quota.Usage(ctx, quota.Subject{Kind: quota.KindAPIKey, ID: key.ID}, userLimits)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询配额使用情况失败"})
+			return
+		}
+		usage.Name = key.Name
+		apiKeys = append(apiKeys, usage)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspace": workspaceUsage,
+		"users":     users,
+		"apiKeys":   apiKeys,
+	})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/templates"
+)
+
+// defaultPermanentCacheSeconds 永久跳转默认允许客户端缓存的时间（秒）
+const defaultPermanentCacheSeconds = 86400
+
+// redirectType 返回短链接实际使用的跳转方式
+func (h *ShortLinkHandler) redirectType(link *models.ShortLink) string {
+	if link.RedirectType != "" {
+		return link.RedirectType
+	}
+	if h.config.RedirectType != "" {
+		return h.config.RedirectType
+	}
+	return models.DefaultRedirectType
+}
+
+// redirect 按短链接的跳转方式返回响应，并设置对应的缓存头
+func (h *ShortLinkHandler) redirect(c *gin.Context, link *models.ShortLink, targetURL string) {
+	switch h.redirectType(link) {
+	case models.RedirectMovedPermanently:
+		c.Header("Cache-Control", h.permanentCacheControl(link))
+		c.Redirect(http.StatusMovedPermanently, targetURL)
+	case models.RedirectPermanent:
+		c.Header("Cache-Control", h.permanentCacheControl(link))
+		c.Redirect(http.StatusPermanentRedirect, targetURL)
+	case models.RedirectFound:
+		// 每次访问都需要经过服务端以便统计
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, targetURL)
+	case models.RedirectMeta:
+		h.renderMetaRedirect(c, targetURL)
+	default:
+		c.Header("Cache-Control", "no-cache")
+		c.Redirect(http.StatusTemporaryRedirect, targetURL)
+	}
+}
+
+// permanentCacheControl 计算永久跳转的Cache-Control，缓存时间不超过短链接的剩余有效期
+func (h *ShortLinkHandler) permanentCacheControl(link *models.ShortLink) string {
+	maxAge := h.config.PermanentCacheSeconds
+	if maxAge <= 0 {
+		maxAge = defaultPermanentCacheSeconds
+	}
+	if remaining := int(time.Until(link.ExpiresAt).Seconds()); remaining < maxAge {
+		maxAge = remaining
+	}
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	// 跳转目标随访问者变化时不允许共享缓存
+	if len(link.Variants) > 0 || len(link.DeviceRules) > 0 || link.HasSocialCard() {
+		return fmt.Sprintf("private, max-age=%d", maxAge)
+	}
+	return fmt.Sprintf("public, max-age=%d", maxAge)
+}
+
+// renderMetaRedirect 返回通过meta refresh和JavaScript跳转的中间页
+func (h *ShortLinkHandler) renderMetaRedirect(c *gin.Context, targetURL string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	h.renderPage(c, http.StatusOK, templates.PageRedirect, gin.H{"url": targetURL})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ReportShortLink 访问者举报短链接
+func (h *ShortLinkHandler) ReportShortLink(c *gin.Context) {
+	var req models.ReportLinkRequest
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	domain := h.requestDomain(c)
+	// 举报不是访问，不更新访问计数
+	shortLink, err := h.store.Lookup(domain.ID, c.Param("code"))
+	if err != nil {
+		// 已停用或隔离的短链接无需再次处理
+		if errors.Is(err, models.ErrLinkDisabled) || errors.Is(err, models.ErrLinkQuarantined) {
+			c.JSON(http.StatusOK, gin.H{"message": "举报已提交，感谢您的反馈"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	report := &models.LinkReport{
+		LinkID:     shortLink.ID,
+		ShortCode:  shortLink.ShortCode,
+		Reason:     strings.TrimSpace(req.Reason),
+		Contact:    strings.TrimSpace(req.Contact),
+		ReporterIP: c.ClientIP(),
+		Status:     models.ReportStatusPending,
+		CreatedAt:  time.Now(),
+	}
+	quarantined, err := h.store.ReportLink(shortLink, report, h.config.Report.QuarantineThreshold)
+	if err != nil {
+		logrus.Errorf("save link report error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交举报失败"})
+		return
+	}
+	if quarantined {
+		logrus.Warnf("short link %s (id: %d) quarantined by visitor reports", shortLink.ShortCode, shortLink.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "举报已提交，感谢您的反馈"})
+}
+
+// GetReports 获取举报列表（审核队列）
+func (h *AdminHandler) GetReports(c *gin.Context) {
+	query := h.db.GetDB().Scopes(h.linkReports(visibleLinks(c))).Order("created_at DESC")
+
+	// 分页参数
+	page := c.DefaultQuery("page", "1")
+	pageSize := c.DefaultQuery("pageSize", "10")
+
+	// 过滤参数，默认只返回待处理的举报
+	if status := c.DefaultQuery("status", models.ReportStatusPending); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if linkID := c.Query("linkId"); linkID != "" {
+		query = query.Where("link_id = ?", linkID)
+	}
+	if shortCode := c.Query("shortCode"); shortCode != "" {
+		query = query.Where("short_code LIKE ?", "%"+shortCode+"%")
+	}
+
+	var total int64
+	if err := query.Model(&models.LinkReport{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "计数查询失败: " + err.Error()})
+		return
+	}
+
+	var reports []models.LinkReport
+	if err := query.Scopes(models.Paginate(page, pageSize)).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询数据失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":   total,
+		"reports": reports,
+	})
+}
+
+// DismissReport 驳回举报，驳回的举报不再计入自动隔离人数
+func (h *AdminHandler) DismissReport(c *gin.Context) {
+	report, ok := h.pendingReport(c)
+	if !ok {
+		return
+	}
+
+	username, _ := c.Get("username")
+	if err := h.db.GetDB().Model(report).Updates(map[string]interface{}{
+		"status":     models.ReportStatusDismissed,
+		"handled_by": fmt.Sprint(username),
+		"handled_at": time.Now(),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "驳回举报失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "举报已驳回"})
+}
+
+// DisableReportedLink 根据举报停用短链接，并将该短链接所有待处理的举报标记为已处理
+func (h *AdminHandler) DisableReportedLink(c *gin.Context) {
+	report, ok := h.pendingReport(c)
+	if !ok {
+		return
+	}
+
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(ownedLinks(c)).Where("id = ?", report.LinkID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	now := time.Now()
+	err := h.db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&link).Updates(map[string]interface{}{
+			"status":            models.LinkStatusDisabled,
+			"status_reason":     "举报核实: " + report.Reason,
+			"status_updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.LinkReport{}).
+			Where("link_id = ? AND status = ?", link.ID, models.ReportStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.ReportStatusActioned,
+				"handled_by": fmt.Sprint(username),
+				"handled_at": now,
+			}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "停用短链接失败"})
+		return
+	}
+
+	// 从缓存中删除短链接，下次访问时重新加载
+	h.db.RemoveFromCache(link.DomainID, link.ShortCode)
+	logrus.Infof("short link %s (id: %d) disabled by %v from report %d", link.ShortCode, link.ID, username, report.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "短链接已停用"})
+}
+
+// linkReports 将举报查询限定在指定范围的短链接内
+func (h *AdminHandler) linkReports(scope func(db *gorm.DB) *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		links := h.db.GetDB().Model(&models.DBShortLink{}).Select("id").Scopes(scope)
+		return db.Where("link_id IN (?)", links)
+	}
+}
+
+// pendingReport 查询待处理的举报，不存在或已处理时返回错误响应
+func (h *AdminHandler) pendingReport(c *gin.Context) (*models.LinkReport, bool) {
+	var report models.LinkReport
+	if err := h.db.GetDB().Scopes(h.linkReports(ownedLinks(c))).Where("id = ?", c.Param("id")).First(&report).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "举报不存在"})
+		return nil, false
+	}
+	if report.Status != models.ReportStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "举报已处理"})
+		return nil, false
+	}
+	return &report, true
+}
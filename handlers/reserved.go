@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// robotsTxt 访问服务默认的robots.txt内容
+const robotsTxt = "User-agent: *\nDisallow:\n"
+
+// HealthCheck 健康检查
+func (h *ShortLinkHandler) HealthCheck(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Favicon 访问服务没有图标，返回204避免浏览器请求被当作短码处理
+func (h *ShortLinkHandler) Favicon(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Status(http.StatusNoContent)
+}
+
+// RobotsTxt 返回robots.txt
+func (h *ShortLinkHandler) RobotsTxt(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.String(http.StatusOK, robotsTxt)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// issueSessionTokens 为登录会话签发访问令牌和刷新令牌并返回登录响应
+func (h *AdminHandler) issueSessionTokens(c *gin.Context, admin *models.SysAdmin, sessionID string) {
+	accessDuration := h.config.JWT.AccessTokenDuration()
+	token, err := utils.GenerateToken(admin.ID, admin.Username, admin.Role, admin.WorkspaceID, sessionID, accessDuration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return
+	}
+
+	refreshToken, err := models.IssueRefreshToken(h.db.GetDB(), &models.RefreshToken{
+		AdminID:   admin.ID,
+		SessionID: sessionID,
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+	}, h.config.JWT.RefreshTokenDuration())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token:                  token,
+		Username:               admin.Username,
+		UserID:                 admin.ID,
+		Role:                   admin.Role,
+		WorkspaceID:            admin.WorkspaceID,
+		RefreshToken:           refreshToken,
+		ExpiresIn:              int64(accessDuration.Seconds()),
+		MustChangePassword:     admin.MustChangePassword,
+		TwoFactorSetupRequired: h.config.TwoFactor.Required && !admin.TOTPEnabled,
+	})
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效
+func (h *AdminHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	record, err := models.RotateRefreshToken(h.db.GetDB(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, models.ErrRefreshTokenReused) {
+			// 已轮换的令牌被再次使用，令牌可能已被盗用，吊销整个会话
+			if err := h.revokeSession(c, record.SessionID); err != nil {
+				logrus.Errorf("refresh token reused, failed to revoke session %s of admin %d, ip: %s, error: %v",
+					record.SessionID, record.AdminID, c.ClientIP(), err)
+			} else {
+				logrus.Warnf("refresh token reused, session %s of admin %d revoked, ip: %s", record.SessionID, record.AdminID, c.ClientIP())
+			}
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已失效，请重新登录"})
+		return
+	}
+
+	var admin models.SysAdmin
+	if err := h.db.GetDB().Where("id = ?", record.AdminID).First(&admin).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已失效，请重新登录"})
+		return
+	}
+
+	h.issueSessionTokens(c, &admin, record.SessionID)
+}
+
+// Logout 退出登录，吊销当前会话的访问令牌和刷新令牌
+func (h *AdminHandler) Logout(c *gin.Context) {
+	value, _ := c.Get("claims")
+	claims, ok := value.(*utils.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	if err := h.revokeSession(c, claims.SessionID); err != nil {
+		logrus.Errorf("revoke session error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+		return
+	}
+	if claims.ExpiresAt != nil {
+		if err := h.revocation.RevokeToken(c.Request.Context(), claims.ID, claims.ExpiresAt.Time); err != nil {
+			logrus.Errorf("revoke access token error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "退出登录失败"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已退出登录"})
+}
+
+// revokeSession 吊销登录会话，先吊销刷新令牌，避免只吊销了访问令牌时刷新令牌仍可换取新的访问令牌
+func (h *AdminHandler) revokeSession(c *gin.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	if err := models.RevokeRefreshSession(h.db.GetDB(), sessionID); err != nil {
+		return fmt.Errorf("吊销刷新令牌失败: %v", err)
+	}
+	if err := h.revocation.RevokeSession(c.Request.Context(), sessionID); err != nil {
+		return fmt.Errorf("吊销访问令牌失败: %v", err)
+	}
+	return nil
+}
+
+// revokeAdminSessions 吊销管理员的所有登录会话，包括刷新令牌和已签发的访问令牌
+// 吊销失败时旧会话仍然有效，调用方需要返回错误
+func (h *AdminHandler) revokeAdminSessions(c *gin.Context, adminID int64) error {
+	if err := models.RevokeAdminRefreshTokens(h.db.GetDB(), adminID); err != nil {
+		return fmt.Errorf("吊销刷新令牌失败: %v", err)
+	}
+	if err := h.revocation.RevokeUser(c.Request.Context(), adminID); err != nil {
+		return fmt.Errorf("吊销访问令牌失败: %v", err)
+	}
+	return nil
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/qiuxsgit/go-short-link/quota"
+	"github.com/qiuxsgit/go-short-link/templates"
 	"github.com/qiuxsgit/go-short-link/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -14,22 +20,79 @@ import (
 type ShortLinkHandler struct {
 	store   models.Store
 	baseURL string
+	config  *conf.AccessServerConfig
+	pages   *templates.Renderer
+	policy  *policy.Engine
+	quota   *quota.Manager
 }
 
 // NewShortLinkHandler 创建一个新的短链接处理器
-func NewShortLinkHandler(store models.Store, baseURL string) *ShortLinkHandler {
+func NewShortLinkHandler(store models.Store, config *conf.AccessServerConfig, pages *templates.Renderer, engine *policy.Engine, quotas *quota.Manager) *ShortLinkHandler {
 	return &ShortLinkHandler{
 		store:   store,
-		baseURL: baseURL,
+		baseURL: config.BaseURL,
+		config:  config,
+		pages:   pages,
+		policy:  engine,
+		quota:   quotas,
 	}
 }
 
 // CreateShortLink 创建短链接
 func (h *ShortLinkHandler) CreateShortLink(c *gin.Context) {
 	var req models.CreateShortLinkRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logrus.Errorf("CreateShortLink bind params error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	// 校验并规范化目标URL
+	rules := urlPolicy(&h.config.URLValidation)
+	var fields []FieldError
+	fields = normalizeURLField(rules, "link", &req.Link, fields)
+	fields = normalizeDeviceRuleURLs(rules, req.DeviceRules, fields)
+	if req.OGImage != "" {
+		fields = normalizeURLField(rules, "ogImage", &req.OGImage, fields)
+	}
+	if len(fields) > 0 {
+		respondFieldErrors(c, fields)
+		return
+	}
+
+	// 按域名策略检查目标URL
+	fields = checkDomainPolicy(h.policy, "link", req.Link, fields)
+	fields = checkDeviceRulePolicy(h.policy, req.DeviceRules, fields)
+	if len(fields) > 0 {
+		logrus.Warnf("CreateShortLink blocked by domain policy, ip: %s, link: %s", c.ClientIP(), req.Link)
+		respondFieldErrors(c, fields)
+		return
+	}
+
+	// 校验设备定向规则
+	if err := req.DeviceRules.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 校验跳转方式
+	if !models.IsValidRedirectType(req.RedirectType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的跳转方式: " + req.RedirectType})
+		return
+	}
+
+	// 选择短链接使用的域名，只能使用默认域名或所属工作区的域名
+	workspaceID := c.GetInt64("workspaceID")
+	domain := h.defaultDomain()
+	if req.Domain != "" {
+		var ok bool
+		if domain, ok = h.store.ResolveDomain(req.Domain); !ok || domain.WorkspaceID != workspaceID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "域名不存在: " + req.Domain})
+			return
+		}
+	}
+
+	// 检查有效期并占用配额
+	checks, ok := h.reserveQuota(c, workspaceID, time.Duration(req.Expire)*time.Second)
+	if !ok {
 		return
 	}
 
@@ -38,20 +101,35 @@ func (h *ShortLinkHandler) CreateShortLink(c *gin.Context) {
 
 	// 创建短链接记录
 	shortLink := &models.ShortLink{
-		OriginalURL: req.Link,
-		ShortCode:   shortCode,
-		CreatedAt:   time.Now(),
-		ExpiresAt:   time.Now().Add(time.Duration(req.Expire) * time.Second),
+		DomainID:         domain.ID,
+		OriginalURL:      req.Link,
+		ShortCode:        shortCode,
+		CreatedAt:        time.Now(),
+		ExpiresAt:        time.Now().Add(time.Duration(req.Expire) * time.Second),
+		DeviceRules:      req.DeviceRules,
+		UTMParams:        req.UTMParams,
+		QueryPassthrough: req.QueryPassthrough,
+		PrefixMode:       req.PrefixMode,
+		RedirectType:     req.RedirectType,
+		ForcePreview:     req.ForcePreview,
+		OGTitle:          req.OGTitle,
+		OGDescription:    req.OGDescription,
+		OGImage:          req.OGImage,
+		Status:           models.LinkStatusActive,
+		WorkspaceID:      workspaceID,
+		CreatedBy:        c.GetInt64("userID"),
+		APIKeyID:         c.GetInt64("apiKeyID"),
 	}
 
 	// 保存到存储
 	if err := h.store.Save(shortLink); err != nil {
+		h.quota.Cancel(c.Request.Context(), checks)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建短链接失败"})
 		return
 	}
 
 	// 构建完整的短链接URL
-	fullShortLink := utils.BuildShortLink(h.baseURL, shortCode)
+	fullShortLink := h.buildShortLink(domain, shortCode)
 
 	// 返回响应
 	c.JSON(http.StatusOK, models.CreateShortLinkResponse{
@@ -59,68 +137,187 @@ func (h *ShortLinkHandler) CreateShortLink(c *gin.Context) {
 	})
 }
 
+// reserveQuota 检查有效期和创建配额，超限时返回错误响应
+func (h *ShortLinkHandler) reserveQuota(c *gin.Context, workspaceID int64, ttl time.Duration) ([]quota.Check, bool) {
+	checks, err := h.quota.Checks(workspaceID, c.GetInt64("userID"), c.GetInt64("apiKeyID"))
+	if err == nil {
+		err = h.quota.Reserve(c.Request.Context(), checks, ttl)
+	}
+	if err != nil {
+		respondQuotaError(c, "CreateShortLink", err)
+		return nil, false
+	}
+	return checks, true
+}
+
+// respondQuotaError 返回配额检查失败的错误响应
+// 有效短链接数超限返回403，当天创建数超限返回429并在Retry-After中给出距离次日零点的秒数，有效期超限返回400
+func respondQuotaError(c *gin.Context, action string, err error) {
+	var exceeded *quota.ExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		logrus.Warnf("%s quota exceeded, %s %d, limit: %s, used: %d, max: %d",
+			action, exceeded.Subject.Kind, exceeded.Subject.ID, exceeded.Limit, exceeded.Used, exceeded.Max)
+		status := http.StatusForbidden
+		switch exceeded.Limit {
+		case quota.LimitTTL:
+			status = http.StatusBadRequest
+		case quota.LimitDailyCreates:
+			status = http.StatusTooManyRequests
+			setRetryAfter(c, quota.RetryAfter(time.Now()))
+		}
+		c.JSON(status, gin.H{
+			"error": exceeded.Error(),
+			"quota": gin.H{"subject": exceeded.Subject.Kind, "limit": exceeded.Limit, "max": exceeded.Max, "used": exceeded.Used},
+		})
+	default:
+		logrus.Errorf("%s quota check failed: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "检查配额失败"})
+	}
+}
+
 // RedirectShortLink 重定向短链接到原始URL
 func (h *ShortLinkHandler) RedirectShortLink(c *gin.Context) {
+	// 根据Host确定短码所属的域名
+	domain := h.requestDomain(c)
+
 	shortCode := c.Param("code")
 	if shortCode == "" {
-		c.HTML(http.StatusNotFound, "404.html", gin.H{
-			"title":   "Page Not Found",
-			"message": "无效的短链接代码",
-		})
+		h.notFound(c, domain)
+		return
+	}
+
+	// 短码后加".qr"时返回二维码
+	if strings.HasSuffix(shortCode, ".qr") {
+		h.serveQRCode(c, domain, strings.TrimSuffix(shortCode, ".qr"))
 		return
 	}
 
+	// 短码后加"+"或带有preview=1参数时只展示预览页
+	query := c.Request.URL.Query()
+	preview := false
+	if strings.HasSuffix(shortCode, "+") {
+		shortCode = strings.TrimSuffix(shortCode, "+")
+		preview = true
+	}
+	if query.Get("preview") == "1" {
+		query.Del("preview")
+		preview = true
+	}
+
 	// 从存储中获取短链接
-	shortLink, err := h.store.Get(shortCode)
+	shortLink, err := h.store.Get(domain.ID, shortCode)
 	if err != nil {
-		// 返回404页面
-		c.HTML(http.StatusNotFound, "", gin.H{})
-		// 如果没有模板，则直接返回HTML
-		if c.Writer.Status() != http.StatusNotFound {
-			return
+		switch {
+		case errors.Is(err, models.ErrLinkExpired):
+			h.renderPage(c, http.StatusGone, templates.PageExpired, nil)
+		case errors.Is(err, models.ErrLinkDisabled):
+			h.renderDisabled(c, models.LinkStatusDisabled)
+		case errors.Is(err, models.ErrLinkQuarantined):
+			h.renderDisabled(c, models.LinkStatusQuarantined)
+		default:
+			h.notFound(c, domain)
 		}
+		return
+	}
+
+	// 强制预览页中确认继续访问时带有continue=1参数，该参数不传给目标URL
+	confirmed := false
+	if shortLink.ForcePreview && query.Get("continue") == "1" {
+		query.Del("continue")
+		confirmed = true
+	}
+
+	// 短码之后的路径只在前缀模式下有效，且不能通过"."或".."跳出目标URL的路径
+	suffix := strings.TrimPrefix(c.Param("rest"), "/")
+	if suffix != "" && (!shortLink.PrefixMode || !isSafePathSuffix(suffix)) {
+		h.notFound(c, domain)
+		return
+	}
+
+	// 选择A/B分流目标
+	targetURL := shortLink.OriginalURL
+	variant := pickVariantForRequest(c, shortLink)
+	if variant != nil {
+		targetURL = variant.URL
+	}
+
+	// 前缀模式下追加路径后缀
+	if suffix != "" {
+		targetURL = appendPathSuffix(shortLink, targetURL, suffix)
+	}
+
+	// 根据设备平台选择跳转目标
+	platform := utils.ParsePlatform(c.Request.UserAgent())
+	rule, targetURL := resolveDeviceTarget(shortLink, platform, targetURL)
+	if len(shortLink.DeviceRules) > 0 || shortLink.HasSocialCard() {
+		c.Writer.Header().Add("Vary", "User-Agent")
+	}
+
+	// 追加UTM参数及透传的查询参数
+	targetURL = applyQueryParams(shortLink, targetURL, query)
+
+	// 向链接预览爬虫返回社交分享卡片，不计为点击
+	if shortLink.HasSocialCard() && utils.IsPreviewCrawler(c.Request.UserAgent()) {
+		h.renderSocialCard(c, shortLink, h.buildShortLink(domain, shortLink.ShortCode), targetURL)
+		return
+	}
+
+	// 主动预览不计为点击
+	if preview {
+		h.renderPreview(c, shortLink, targetURL, targetURL)
+		return
+	}
+
+	// 强制预览的短链接需要访问者确认后再跳转，确认后才计为点击
+	if shortLink.ForcePreview && !confirmed {
+		h.renderPreview(c, shortLink, targetURL, continueURL(c, query))
+		return
+	}
+
+	// 记录点击事件
+	click := &models.ShortLinkClick{
+		LinkID:    shortLink.ID,
+		Platform:  platform,
+		IP:        c.ClientIP(),
+		CreatedAt: time.Now(),
+	}
+	if variant != nil {
+		click.VariantID = variant.ID
+	}
+	h.store.RecordClick(click)
+
+	if h.hasDeepLink(rule) {
+		h.renderDeepLinkPage(c, rule, targetURL)
+		return
+	}
+
+	// 重定向到目标URL
+	h.redirect(c, shortLink, targetURL)
+}
+
+// buildShortLink 按域名和路径前缀构建完整的短链接URL
+func (h *ShortLinkHandler) buildShortLink(domain *models.Domain, shortCode string) string {
+	return utils.BuildShortLink(domain.BaseURL, h.config.ShortPathPrefix(), shortCode)
+}
+
+// renderDisabled 访问已停用或隔离的短链接时跳转到配置的提示地址，未配置时返回停用提示页面
+func (h *ShortLinkHandler) renderDisabled(c *gin.Context, status string) {
+	c.Header("Cache-Control", "no-store")
+	if h.config.DisabledPageURL != "" {
+		c.Redirect(http.StatusFound, h.config.DisabledPageURL)
+		return
+	}
+	h.renderPage(c, http.StatusForbidden, templates.PageDisabled, gin.H{"status": status})
+}
+
+// RenderRateLimited 返回访问过于频繁的429页面
+func (h *ShortLinkHandler) RenderRateLimited(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	h.renderPage(c, http.StatusTooManyRequests, templates.PageRateLimited, nil)
+}
 
-		// 直接返回简单的HTML页面
-		notFoundHTML := `<!DOCTYPE html>
-<html>
-<head>
-    <title>Page Not Found</title>
-    <style>
-        body {
-            font-family: Arial, sans-serif;
-            text-align: center;
-            padding-top: 100px;
-            background-color: #f7f7f7;
-        }
-        .container {
-            max-width: 600px;
-            margin: 0 auto;
-            padding: 20px;
-            background-color: #fff;
-            border-radius: 5px;
-            box-shadow: 0 2px 10px rgba(0,0,0,0.1);
-        }
-        h1 {
-            color: #e74c3c;
-        }
-        p {
-            color: #7f8c8d;
-            font-size: 18px;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <h1>404 - Page Not Found</h1>
-        <p>抱歉，您访问的短链接不存在或已过期。</p>
-    </div>
-</body>
-</html>`
-		c.Header("Content-Type", "text/html; charset=utf-8")
-		c.String(http.StatusNotFound, notFoundHTML)
-		return
-	}
-
-	// 重定向到原始URL
-	c.Redirect(http.StatusTemporaryRedirect, shortLink.OriginalURL)
+// renderNotFound 返回短链接不存在的404页面
+func (h *ShortLinkHandler) renderNotFound(c *gin.Context) {
+	h.renderPage(c, http.StatusNotFound, templates.PageNotFound, nil)
 }
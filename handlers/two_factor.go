@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"image/color"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// 两步验证的默认值
+const (
+	defaultTOTPIssuer     = "go-short-link"
+	defaultPreAuthMinutes = 5
+)
+
+// preAuthDuration 两步验证中间令牌的有效时间
+func (h *AdminHandler) preAuthDuration() time.Duration {
+	minutes := h.config.TwoFactor.PreAuthMinutes
+	if minutes <= 0 {
+		minutes = defaultPreAuthMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// totpIssuer 验证器App中显示的发行方名称
+func (h *AdminHandler) totpIssuer() string {
+	if h.config.TwoFactor.Issuer != "" {
+		return h.config.TwoFactor.Issuer
+	}
+	return defaultTOTPIssuer
+}
+
+// currentAdmin 查询当前登录的管理员，不存在时返回错误响应
+func (h *AdminHandler) currentAdmin(c *gin.Context) (*models.SysAdmin, bool) {
+	userID, _ := c.Get("userID")
+	var admin models.SysAdmin
+	if err := h.db.GetDB().Where("id = ?", userID).First(&admin).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return nil, false
+	}
+	return &admin, true
+}
+
+// verifyTOTP 校验验证器App中的验证码，成功时记录已使用的时间步
+func (h *AdminHandler) verifyTOTP(admin *models.SysAdmin, code string) bool {
+	step, ok := utils.ValidateTOTP(admin.TOTPSecret, code, time.Now(), admin.TOTPLastStep)
+	if !ok {
+		return false
+	}
+	// 按时间步条件更新，并发请求中只有一个能使用同一验证码
+	result := h.db.GetDB().Model(&models.SysAdmin{}).
+		Where("id = ? AND totp_last_step < ?", admin.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	admin.TOTPLastStep = step
+	return true
+}
+
+// verifySecondFactor 校验验证码或恢复码
+func (h *AdminHandler) verifySecondFactor(admin *models.SysAdmin, code string) bool {
+	if h.verifyTOTP(admin, code) {
+		return true
+	}
+	if models.UseRecoveryCode(h.db.GetDB(), admin.ID, code) {
+		logrus.Warnf("admin %s used a recovery code, %d remaining", admin.Username, models.CountRecoveryCodes(h.db.GetDB(), admin.ID))
+		return true
+	}
+	return false
+}
+
+// LoginTwoFactor 登录第二步：使用中间令牌和验证码（或恢复码）换取访问令牌
+func (h *AdminHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.LoginTwoFactorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	claims, err := utils.ParsePreAuthToken(req.PreAuthToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "验证已过期，请重新登录"})
+		return
+	}
+
+	security := h.config.LoginSecurity.WithDefaults()
+	now := time.Now()
+	if !h.checkLoginIP(c, claims.Username, security, now) {
+		return
+	}
+
+	var admin models.SysAdmin
+	if err := h.db.GetDB().Where("id = ?", claims.UserID).First(&admin).Error; err != nil || !admin.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "验证已过期，请重新登录"})
+		return
+	}
+	if !h.checkLoginAccount(c, &admin, security, now) {
+		return
+	}
+
+	if !h.verifySecondFactor(&admin, req.Code) {
+		result := h.recordLoginFailure(&admin, security, now, models.LoginResultBadTwoFactor)
+		h.auditLogin(c, admin.ID, admin.Username, result)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "验证码错误"})
+		return
+	}
+
+	h.completeLogin(c, &admin, now)
+}
+
+// GetTwoFactorStatus 获取当前管理员的两步验证状态
+func (h *AdminHandler) GetTwoFactorStatus(c *gin.Context) {
+	admin, ok := h.currentAdmin(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                admin.TOTPEnabled,
+		"required":               h.config.TwoFactor.Required,
+		"recoveryCodesRemaining": models.CountRecoveryCodes(h.db.GetDB(), admin.ID),
+	})
+}
+
+// EnrollTwoFactor 生成待绑定的TOTP密钥，返回密钥、otpauth地址和二维码，调用VerifyTwoFactor确认后生效
+func (h *AdminHandler) EnrollTwoFactor(c *gin.Context) {
+	admin, ok := h.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if admin.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "两步验证已启用"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成密钥失败"})
+		return
+	}
+	if err := h.db.GetDB().Model(admin).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存密钥失败"})
+		return
+	}
+
+	uri := utils.TOTPProvisioningURI(h.totpIssuer(), admin.Username, secret)
+	png, _, err := utils.RenderQRCode(uri, utils.QROptions{
+		Format:     utils.QRFormatPNG,
+		Size:       256,
+		Margin:     4,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成二维码失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret": secret,
+		"uri":    uri,
+		"qrCode": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// VerifyTwoFactor 使用验证码确认绑定，启用两步验证并返回一次性恢复码
+func (h *AdminHandler) VerifyTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	admin, ok := h.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if admin.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "两步验证已启用"})
+		return
+	}
+	if admin.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请先获取绑定密钥"})
+		return
+	}
+	if !h.verifyTOTP(admin, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "验证码错误"})
+		return
+	}
+
+	codes, err := models.ReplaceRecoveryCodes(h.db.GetDB(), admin.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成恢复码失败"})
+		return
+	}
+	if err := h.db.GetDB().Model(admin).Update("totp_enabled", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "启用两步验证失败"})
+		return
+	}
+
+	logrus.Infof("admin %s enabled two-factor authentication", admin.Username)
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部失效
+func (h *AdminHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	admin, ok := h.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if !admin.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "两步验证未启用"})
+		return
+	}
+	if !h.verifyTOTP(admin, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "验证码错误"})
+		return
+	}
+
+	codes, err := models.ReplaceRecoveryCodes(h.db.GetDB(), admin.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成恢复码失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// DisableTwoFactor 关闭两步验证，需要当前密码和验证码（或恢复码）
+func (h *AdminHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.DisableTwoFactorRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if h.config.TwoFactor.Required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "系统要求所有管理员启用两步验证，不能关闭"})
+		return
+	}
+
+	admin, ok := h.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if !admin.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "两步验证未启用"})
+		return
+	}
+	if !models.CheckPassword(admin.Password, req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "当前密码错误"})
+		return
+	}
+	if !h.verifySecondFactor(admin, req.Code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "验证码错误"})
+		return
+	}
+
+	if err := h.db.GetDB().Model(admin).Updates(map[string]interface{}{
+		"totp_secret":    "",
+		"totp_enabled":   false,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "关闭两步验证失败"})
+		return
+	}
+	models.DeleteRecoveryCodes(h.db.GetDB(), admin.ID)
+
+	logrus.Infof("admin %s disabled two-factor authentication", admin.Username)
+	c.JSON(http.StatusOK, gin.H{"message": "两步验证已关闭"})
+}
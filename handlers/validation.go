@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/qiuxsgit/go-short-link/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// 校验错误使用JSON字段名，便于前端定位到具体字段
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// respondFieldErrors 返回结构化的字段校验错误
+func respondFieldErrors(c *gin.Context, fields []FieldError) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "参数校验失败",
+		"fields": fields,
+	})
+}
+
+// bindJSON 解析JSON请求体，失败时返回错误响应
+// 字段校验失败时返回每个字段的错误信息，请求体格式错误时返回通用错误
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	return handleBindError(c, obj, c.ShouldBindJSON(obj))
+}
+
+// bindRequest 按Content-Type解析JSON或表单请求，失败时返回错误响应
+func bindRequest(c *gin.Context, obj interface{}) bool {
+	return handleBindError(c, obj, c.ShouldBind(obj))
+}
+
+// handleBindError 将请求解析错误转换为错误响应，解析成功时返回true
+func handleBindError(c *gin.Context, obj interface{}, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		logrus.Errorf("bind params error: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return false
+	}
+
+	root := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	fields := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		fields[i] = FieldError{
+			Field:   fieldPath(root, fe),
+			Message: validationMessage(fe),
+		}
+	}
+	respondFieldErrors(c, fields)
+	return false
+}
+
+// fieldPath 返回去掉请求结构体名称的字段路径，如 variants[0].url
+func fieldPath(root string, fe validator.FieldError) string {
+	if root == "" {
+		return fe.Namespace()
+	}
+	return strings.TrimPrefix(fe.Namespace(), root+".")
+}
+
+// validationMessage 将校验规则转换为错误信息
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "不能为空"
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("长度不能超过%s", fe.Param())
+		}
+		return fmt.Sprintf("不能大于%s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("长度不能少于%s", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("至少包含%s项", fe.Param())
+		}
+		return fmt.Sprintf("不能小于%s", fe.Param())
+	case "url", "eq=|url":
+		return "不是有效的URL"
+	case "eqfield":
+		return fmt.Sprintf("必须与%s一致", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("必须是以下值之一: %s", fe.Param())
+	}
+	return fmt.Sprintf("校验失败: %s", fe.Tag())
+}
+
+// urlPolicy 根据配置创建目标URL校验规则
+func urlPolicy(config *conf.URLValidationConfig) utils.URLPolicy {
+	return utils.URLPolicy{
+		AllowedSchemes:    config.AllowedSchemes,
+		MaxLength:         config.MaxLength,
+		AllowPrivateHosts: config.AllowPrivateHosts,
+		DeepLinkSchemes:   config.DeepLinkSchemes,
+	}
+}
+
+// normalizeURLField 校验并规范化目标URL字段，失败时追加字段错误
+func normalizeURLField(rules utils.URLPolicy, field string, target *string, fields []FieldError) []FieldError {
+	normalized, err := rules.NormalizeURL(*target)
+	if err != nil {
+		return append(fields, FieldError{Field: field, Message: err.Error()})
+	}
+	*target = normalized
+	return fields
+}
+
+// normalizeDeviceRuleURLs 校验并规范化设备规则中的网页地址，深度链接只校验协议
+func normalizeDeviceRuleURLs(rules utils.URLPolicy, deviceRules []models.DeviceRule, fields []FieldError) []FieldError {
+	for i := range deviceRules {
+		if deviceRules[i].URL != "" {
+			fields = normalizeURLField(rules, fmt.Sprintf("deviceRules[%d].url", i), &deviceRules[i].URL, fields)
+		}
+		if deviceRules[i].DeepLink != "" {
+			if err := rules.NormalizeDeepLink(deviceRules[i].DeepLink); err != nil {
+				fields = append(fields, FieldError{Field: fmt.Sprintf("deviceRules[%d].deepLink", i), Message: err.Error()})
+			}
+		}
+	}
+	return fields
+}
+
+// checkDomainPolicy 按域名策略检查目标URL，被拒绝时追加字段错误
+func checkDomainPolicy(engine *policy.Engine, field, target string, fields []FieldError) []FieldError {
+	if decision := engine.CheckURL(target); !decision.Allowed {
+		return append(fields, FieldError{Field: field, Message: decision.Reason})
+	}
+	return fields
+}
+
+// checkDeviceRulePolicy 按域名策略检查设备规则中的网页地址和深度链接，intent://链接同时检查回退网页地址
+func checkDeviceRulePolicy(engine *policy.Engine, deviceRules []models.DeviceRule, fields []FieldError) []FieldError {
+	for i := range deviceRules {
+		for _, target := range deviceRules[i].PolicyTargets() {
+			fields = checkDomainPolicy(engine, fmt.Sprintf("deviceRules[%d].%s", i, target.Field), target.URL, fields)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+)
+
+// variantCookiePrefix A/B分流粘性Cookie的名称前缀
+const variantCookiePrefix = "gsl_v_"
+
+// pickVariantForRequest 为当前访问者选择分流目标，并写入粘性Cookie
+func pickVariantForRequest(c *gin.Context, link *models.ShortLink) *models.ShortLinkVariant {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+
+	cookieName := variantCookiePrefix + link.ShortCode
+
+	// 优先使用Cookie中记录的分流目标
+	var stickyID int64
+	if value, err := c.Cookie(cookieName); err == nil {
+		stickyID, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	// 没有Cookie时按IP哈希选择
+	variant := models.PickVariant(link.Variants, stickyID, c.ClientIP()+"|"+link.ShortCode)
+	if variant.ID != stickyID {
+		maxAge := int(time.Until(link.ExpiresAt).Seconds())
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(cookieName, strconv.FormatInt(variant.ID, 10), maxAge, "/", "", false, true)
+	}
+
+	return variant
+}
+
+// GetVariants 获取短链接的分流目标列表
+func (h *AdminHandler) GetVariants(c *gin.Context) {
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(visibleLinks(c)).Where("id = ?", c.Param("id")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	var variants []models.ShortLinkVariant
+	if err := h.db.GetDB().Where("link_id = ?", link.ID).Order("id ASC").Find(&variants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询分流目标失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variants": variants})
+}
+
+// SetVariants 设置短链接的分流目标（整体替换），传空数组表示关闭分流
+func (h *AdminHandler) SetVariants(c *gin.Context) {
+	var req struct {
+		Variants []models.VariantRequest `json:"variants" binding:"dive"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	// 校验并规范化分流目标URL，并按域名策略检查
+	rules := urlPolicy(&h.config.Server.Access.URLValidation)
+	var fields []FieldError
+	for i := range req.Variants {
+		field := fmt.Sprintf("variants[%d].url", i)
+		if variantFields := normalizeURLField(rules, field, &req.Variants[i].URL, nil); len(variantFields) > 0 {
+			fields = append(fields, variantFields...)
+			continue
+		}
+		fields = checkDomainPolicy(h.policy, field, req.Variants[i].URL, fields)
+	}
+	if len(fields) > 0 {
+		respondFieldErrors(c, fields)
+		return
+	}
+
+	if err := models.ValidateVariants(req.Variants); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(ownedLinks(c)).Where("id = ?", c.Param("id")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	// 在事务中替换分流目标
+	tx := h.db.GetDB().Begin()
+	if err := tx.Where("link_id = ?", link.ID).Delete(&models.ShortLinkVariant{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除旧分流目标失败"})
+		return
+	}
+
+	variants := make([]models.ShortLinkVariant, len(req.Variants))
+	for i, v := range req.Variants {
+		variants[i] = models.ShortLinkVariant{
+			LinkID:    link.ID,
+			Name:      v.Name,
+			URL:       v.URL,
+			Weight:    v.Weight,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Create(&variants[i]).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "保存分流目标失败"})
+			return
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交事务失败"})
+		return
+	}
+
+	// 从缓存中删除短链接，下次访问时重新加载
+	h.db.RemoveFromCache(link.DomainID, link.ShortCode)
+
+	c.JSON(http.StatusOK, gin.H{"variants": variants})
+}
+
+// VariantStats 单个分流目标的访问统计
+type VariantStats struct {
+	VariantID int64   `json:"variantId"`
+	Name      string  `json:"name"`
+	URL       string  `json:"url"`
+	Weight    int     `json:"weight"`
+	Clicks    int64   `json:"clicks"`
+	Ratio     float64 `json:"ratio"`
+}
+
+// GetLinkStats 获取短链接的点击统计及分流目标明细
+func (h *AdminHandler) GetLinkStats(c *gin.Context) {
+	var link models.DBShortLink
+	if err := h.db.GetDB().Scopes(visibleLinks(c)).Where("id = ?", c.Param("id")).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "短链接不存在"})
+		return
+	}
+
+	// 按分流目标汇总点击数
+	var rows []struct {
+		VariantID int64
+		Clicks    int64
+	}
+	if err := h.db.GetDB().Model(&models.ShortLinkClick{}).
+		Select("variant_id, COUNT(1) AS clicks").
+		Where("link_id = ?", link.ID).
+		Group("variant_id").
+		Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询点击统计失败: " + err.Error()})
+		return
+	}
+
+	var totalClicks int64
+	clicksByVariant := make(map[int64]int64)
+	for _, row := range rows {
+		clicksByVariant[row.VariantID] = row.Clicks
+		totalClicks += row.Clicks
+	}
+
+	var variants []models.ShortLinkVariant
+	if err := h.db.GetDB().Where("link_id = ?", link.ID).Order("id ASC").Find(&variants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询分流目标失败"})
+		return
+	}
+
+	variantStats := make([]VariantStats, 0, len(variants)+1)
+	for _, v := range variants {
+		variantStats = append(variantStats, VariantStats{
+			VariantID: v.ID,
+			Name:      v.Name,
+			URL:       v.URL,
+			Weight:    v.Weight,
+			Clicks:    clicksByVariant[v.ID],
+		})
+		delete(clicksByVariant, v.ID)
+	}
+
+	// 未经分流（或分流目标已删除）的点击
+	var otherClicks int64
+	for _, clicks := range clicksByVariant {
+		otherClicks += clicks
+	}
+	if otherClicks > 0 {
+		variantStats = append(variantStats, VariantStats{
+			Name:   "default",
+			URL:    link.OriginalURL,
+			Clicks: otherClicks,
+		})
+	}
+
+	if totalClicks > 0 {
+		for i := range variantStats {
+			variantStats[i].Ratio = float64(variantStats[i].Clicks) / float64(totalClicks)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"linkId":      link.ID,
+		"accessCount": link.AccessCount,
+		"totalClicks": totalClicks,
+		"variants":    variantStats,
+	})
+}
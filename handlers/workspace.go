@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultWorkspace 返回默认工作区，默认工作区不保存在数据库中
+func defaultWorkspace() models.Workspace {
+	return models.Workspace{ID: models.DefaultWorkspaceID, Name: models.DefaultWorkspaceName}
+}
+
+// GetCurrentWorkspace 获取当前用户所属的工作区
+func (h *AdminHandler) GetCurrentWorkspace(c *gin.Context) {
+	workspaceID := c.GetInt64("workspaceID")
+	if workspaceID == models.DefaultWorkspaceID {
+		c.JSON(http.StatusOK, defaultWorkspace())
+		return
+	}
+
+	var workspace models.Workspace
+	if err := h.db.GetDB().Where("id = ?", workspaceID).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "工作区不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+// GetWorkspaces 获取工作区列表，第一项为默认工作区
+func (h *AdminHandler) GetWorkspaces(c *gin.Context) {
+	var workspaces []models.Workspace
+	if err := h.db.GetDB().Order("created_at ASC").Find(&workspaces).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询工作区失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workspaces": append([]models.Workspace{defaultWorkspace()}, workspaces...),
+	})
+}
+
+// CreateWorkspace 创建工作区，创建后通过管理员接口为工作区添加成员
+func (h *AdminHandler) CreateWorkspace(c *gin.Context) {
+	var req models.WorkspaceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !h.workspaceNameAvailable(c, req.Name, models.DefaultWorkspaceID) {
+		return
+	}
+
+	username, _ := c.Get("username")
+	workspace := models.Workspace{
+		Name:            req.Name,
+		MaxActiveLinks:  req.MaxActiveLinks,
+		MaxDailyCreates: req.MaxDailyCreates,
+		MaxTTLSeconds:   req.MaxTTLSeconds,
+		CreatedBy:       fmt.Sprint(username),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if err := h.db.GetDB().Create(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建工作区失败"})
+		return
+	}
+
+	logrus.Infof("workspace %s (id: %d) created by %v", workspace.Name, workspace.ID, username)
+	c.JSON(http.StatusOK, workspace)
+}
+
+// UpdateWorkspace 修改工作区名称和配额
+func (h *AdminHandler) UpdateWorkspace(c *gin.Context) {
+	var req models.WorkspaceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	var workspace models.Workspace
+	if err := h.db.GetDB().Where("id = ?", c.Param("id")).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "工作区不存在"})
+		return
+	}
+	if !h.workspaceNameAvailable(c, req.Name, workspace.ID) {
+		return
+	}
+
+	if err := h.db.GetDB().Model(&workspace).Updates(map[string]interface{}{
+		"name":              req.Name,
+		"max_active_links":  req.MaxActiveLinks,
+		"max_daily_creates": req.MaxDailyCreates,
+		"max_ttl_seconds":   req.MaxTTLSeconds,
+		"updated_at":        time.Now(),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新工作区失败"})
+		return
+	}
+	workspace.Name = req.Name
+	workspace.MaxActiveLinks = req.MaxActiveLinks
+	workspace.MaxDailyCreates = req.MaxDailyCreates
+	workspace.MaxTTLSeconds = req.MaxTTLSeconds
+	c.JSON(http.StatusOK, workspace)
+}
+
+// DeleteWorkspace 删除工作区，工作区内仍有成员、短链接、域名或有效的API密钥时不允许删除
+func (h *AdminHandler) DeleteWorkspace(c *gin.Context) {
+	var workspace models.Workspace
+	if err := h.db.GetDB().Where("id = ?", c.Param("id")).First(&workspace).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "工作区不存在"})
+		return
+	}
+
+	scope := models.InWorkspace(workspace.ID)
+	resources := []struct {
+		model interface{}
+		where string
+		name  string
+	}{
+		{&models.SysAdmin{}, "", "成员"},
+		{&models.DBShortLink{}, "", "短链接"},
+		{&models.Domain{}, "", "域名"},
+		{&models.APIKey{}, "revoked_at IS NULL", "API密钥"},
+	}
+	for _, resource := range resources {
+		query := h.db.GetDB().Model(resource.model).Scopes(scope)
+		if resource.where != "" {
+			query = query.Where(resource.where)
+		}
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询工作区资源失败"})
+			return
+		}
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("工作区内仍有%s，无法删除", resource.name)})
+			return
+		}
+	}
+
+	if err := h.db.GetDB().Delete(&workspace).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除工作区失败"})
+		return
+	}
+
+	username, _ := c.Get("username")
+	logrus.Infof("workspace %s (id: %d) deleted by %v", workspace.Name, workspace.ID, username)
+	c.JSON(http.StatusOK, gin.H{"message": "工作区已删除"})
+}
+
+// workspaceNameAvailable 检查工作区名称是否可用，名称重复时返回错误响应
+func (h *AdminHandler) workspaceNameAvailable(c *gin.Context, name string, excludeID int64) bool {
+	if name == models.DefaultWorkspaceName {
+		c.JSON(http.StatusConflict, gin.H{"error": "工作区已存在: " + name})
+		return false
+	}
+	var count int64
+	h.db.GetDB().Model(&models.Workspace{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "工作区已存在: " + name})
+		return false
+	}
+	return true
+}
@@ -19,7 +19,7 @@ func main() {
 	defer application.Cleanup()
 
 	// 创建并初始化服务器
-	srv := server.NewServer(application.Config, application.Store)
+	srv := server.NewServer(application.Config, application.Store, application.Policy, application.RateLimiter, application.Quota, application.TokenRevocation)
 	srv.Initialize()
 
 	// 启动定时任务调度器
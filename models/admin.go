@@ -3,6 +3,7 @@ package models
 import (
 	"crypto/rand"
 	"fmt"
+	"math/big"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -11,12 +12,27 @@ import (
 
 // SysAdmin 系统管理员模型
 type SysAdmin struct {
-	ID        int64     `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
-	Username  string    `gorm:"uniqueIndex;type:varchar(50);not null"`
-	Password  string    `gorm:"type:varchar(100);not null"`
+	ID       int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
+	Username string `gorm:"uniqueIndex;type:varchar(50);not null"`
+	Password string `gorm:"type:varchar(100);not null"`
+	// WorkspaceID 所属工作区，升级前已存在的管理员属于默认工作区
+	WorkspaceID int64 `gorm:"index;default:0"`
+	// Role 在所属工作区内的角色，升级前已存在的管理员迁移为owner
+	Role      string    `gorm:"type:varchar(20);not null;default:'owner'"`
 	LastLogin time.Time `gorm:"type:datetime"`
-	CreatedAt time.Time `gorm:"type:datetime;not null"`
-	UpdatedAt time.Time `gorm:"type:datetime;not null"`
+	// FailedAttempts 连续登录失败次数，登录成功或解锁后清零
+	FailedAttempts int        `gorm:"type:int;not null;default:0"`
+	LastFailedAt   *time.Time `gorm:"type:datetime"`
+	LockedUntil    *time.Time `gorm:"type:datetime"`
+	// MustChangePassword 为true时必须先修改密码才能使用其他管理接口
+	MustChangePassword bool `gorm:"not null;default:false"`
+	// TOTPSecret 两步验证密钥，TOTPEnabled为false时是尚未确认的待绑定密钥
+	TOTPSecret  string `gorm:"type:varchar(64)"`
+	TOTPEnabled bool   `gorm:"not null;default:false"`
+	// TOTPLastStep 最近一次使用的验证码时间步，防止验证码重复使用
+	TOTPLastStep int64     `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"type:datetime;not null"`
+	UpdatedAt    time.Time `gorm:"type:datetime;not null"`
 }
 
 // TableName 设置表名
@@ -24,14 +40,45 @@ func (SysAdmin) TableName() string {
 	return "sys_admin"
 }
 
-// GenerateRandomPassword 生成随机6位数字密码
-func GenerateRandomPassword() string {
-	b := make([]byte, 3)
-	rand.Read(b)
-	// 将随机字节转换为6位数字
-	num := int(b[0])<<16 | int(b[1])<<8 | int(b[2])
-	num = num%900000 + 100000 // 确保是6位数字
-	return fmt.Sprintf("%d", num)
+// passwordAlphabet 随机密码使用的字符，去掉了容易混淆的字符
+const passwordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789!@#$%^&*-_=+"
+
+// randomPasswordLength 随机密码长度
+const randomPasswordLength = 20
+
+// GenerateRandomPassword 生成20位包含大小写字母、数字和符号的随机密码
+func GenerateRandomPassword() (string, error) {
+	b := make([]byte, randomPasswordLength)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("生成随机密码失败: %v", err)
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// IsLocked 检查账户是否处于锁定状态
+func (a *SysAdmin) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// CreateAdminRequest 创建管理员请求，未提供密码时生成随机初始密码
+type CreateAdminRequest struct {
+	Username string `json:"username" binding:"required,max=50"`
+	Password string `json:"password" binding:"omitempty,min=6"`
+	Role     string `json:"role" binding:"required,oneof=owner admin editor viewer"`
+	// WorkspaceID 所属工作区，默认为当前用户的工作区；只有平台所有者可以在其他工作区创建账户
+	WorkspaceID *int64 `json:"workspaceId"`
+}
+
+// UpdateAdminRequest 修改管理员请求，只更新提供的字段
+type UpdateAdminRequest struct {
+	Role *string `json:"role" binding:"omitempty,oneof=owner admin editor viewer"`
+	// ResetPassword 为true时重置为随机密码，下次登录后必须修改
+	ResetPassword bool `json:"resetPassword"`
 }
 
 // HashPassword 对密码进行哈希处理
@@ -52,8 +99,11 @@ func EnsureAdminExists(db *gorm.DB) (string, error) {
 	db.Model(&SysAdmin{}).Count(&count)
 
 	if count == 0 {
-		// 生成随机密码
-		password := GenerateRandomPassword()
+		// 生成随机密码，首次登录后必须修改
+		password, err := GenerateRandomPassword()
+		if err != nil {
+			return "", err
+		}
 		hashedPassword, err := HashPassword(password)
 		if err != nil {
 			return "", err
@@ -61,11 +111,13 @@ func EnsureAdminExists(db *gorm.DB) (string, error) {
 
 		// 创建默认管理员
 		admin := &SysAdmin{
-			Username:  "admin",
-			Password:  hashedPassword,
-			LastLogin: time.Now(), // 设置为当前时间而不是零值
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			Username:           "admin",
+			Password:           hashedPassword,
+			Role:               RoleOwner,
+			LastLogin:          time.Now(), // 设置为当前时间而不是零值
+			MustChangePassword: true,
+			CreatedAt:          time.Now(),
+			UpdatedAt:          time.Now(),
 		}
 
 		if err := db.Create(admin).Error; err != nil {
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix API密钥的固定前缀，用于区分API密钥和JWT令牌
+const APIKeyPrefix = "gsl_"
+
+// API密钥权限范围
+const (
+	ScopeLinkCreate = "link:create" // 创建短链接
+)
+
+// apiKeyTouchInterval 最后使用时间的更新间隔，避免每次请求都写数据库
+const apiKeyTouchInterval = time.Minute
+
+var (
+	// ErrInvalidAPIKey API密钥无效或已吊销
+	ErrInvalidAPIKey = errors.New("API密钥无效")
+	// ErrExpiredAPIKey API密钥已过期
+	ErrExpiredAPIKey = errors.New("API密钥已过期")
+)
+
+// APIKey 用于程序调用的API密钥，只保存密钥的SHA-256哈希
+type APIKey struct {
+	ID   int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	Name string `gorm:"type:varchar(100);not null" json:"name"`
+	// WorkspaceID 所属工作区，使用API密钥创建的短链接属于该工作区
+	WorkspaceID int64      `gorm:"index;default:0" json:"workspaceId"`
+	Prefix      string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash     string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Scopes      string     `gorm:"type:varchar(255);not null" json:"-"`
+	ExpiresAt   *time.Time `gorm:"type:datetime" json:"expiresAt"`
+	LastUsedAt  *time.Time `gorm:"type:datetime" json:"lastUsedAt"`
+	RevokedAt   *time.Time `gorm:"type:datetime" json:"revokedAt"`
+	CreatedBy   string     `gorm:"type:varchar(50)" json:"createdBy"`
+	CreatedAt   time.Time  `gorm:"type:datetime;not null" json:"createdAt"`
+}
+
+// TableName 设置表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// CreateAPIKeyRequest 创建API密钥的请求
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=link:create"`
+	Expire int64    `json:"expire" binding:"min=0"` // 有效期（秒），为0时永不过期
+}
+
+// APIKeyResponse API密钥信息，Key只在创建时返回
+type APIKeyResponse struct {
+	APIKey
+	ScopeList []string `json:"scopes"`
+	Key       string   `json:"key,omitempty"`
+}
+
+// ToResponse 转换为API响应
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{APIKey: *k, ScopeList: k.ScopeList()}
+}
+
+// ScopeList 返回权限范围列表
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope 检查API密钥是否拥有指定权限
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateAPIKey 生成新的API密钥，返回明文密钥和待保存的记录
+func GenerateAPIKey(name string, scopes []string, expire time.Duration) (string, *APIKey, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	key := APIKeyPrefix + hex.EncodeToString(b)
+
+	record := &APIKey{
+		Name:      name,
+		Prefix:    key[:len(APIKeyPrefix)+8],
+		KeyHash:   HashAPIKey(key),
+		Scopes:    strings.Join(scopes, ","),
+		CreatedAt: time.Now(),
+	}
+	if expire > 0 {
+		expiresAt := time.Now().Add(expire)
+		record.ExpiresAt = &expiresAt
+	}
+	return key, record, nil
+}
+
+// HashAPIKey 计算API密钥的哈希，密钥为高熵随机串，使用SHA-256即可
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey 检查凭证是否为API密钥格式
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// AuthenticateAPIKey 校验API密钥并更新最后使用时间
+func AuthenticateAPIKey(db *gorm.DB, key string) (*APIKey, error) {
+	var record APIKey
+	if err := db.Where("key_hash = ?", HashAPIKey(key)).First(&record).Error; err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if record.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if record.ExpiresAt != nil && now.After(*record.ExpiresAt) {
+		return nil, ErrExpiredAPIKey
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > apiKeyTouchInterval {
+		db.Model(&record).Update("last_used_at", now)
+		record.LastUsedAt = &now
+	}
+	return &record, nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/qiuxsgit/go-short-link/utils"
+)
+
+// DefaultDeepLinkTimeout 唤起App失败后回退到网页的默认等待时间（毫秒）
+const DefaultDeepLinkTimeout = 1500
+
+// DeviceRule 按设备平台定向的跳转规则
+type DeviceRule struct {
+	Platform        string `json:"platform"`                  // 平台: ios, android, desktop
+	URL             string `json:"url,omitempty"`             // 该平台的目标URL（如App Store、Google Play地址），为空时使用原始URL
+	DeepLink        string `json:"deepLink,omitempty"`        // 可选的App深度链接（如 myapp://path 或 intent://...）
+	FallbackTimeout int    `json:"fallbackTimeout,omitempty"` // 唤起App失败后回退到网页的等待时间（毫秒）
+}
+
+// DeviceRules 设备定向规则列表，以JSON格式存储在数据库中
+type DeviceRules []DeviceRule
+
+// Value 实现driver.Valuer接口
+func (r DeviceRules) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "", nil
+	}
+	return valueJSON(r)
+}
+
+// Scan 实现sql.Scanner接口
+func (r *DeviceRules) Scan(value interface{}) error {
+	return scanJSON(value, r)
+}
+
+// Validate 校验设备规则
+func (r DeviceRules) Validate() error {
+	seen := make(map[string]bool)
+	for _, rule := range r {
+		if !utils.IsValidPlatform(rule.Platform) {
+			return fmt.Errorf("不支持的设备平台: %s", rule.Platform)
+		}
+		if seen[rule.Platform] {
+			return fmt.Errorf("设备平台重复: %s", rule.Platform)
+		}
+		seen[rule.Platform] = true
+
+		if rule.URL == "" && rule.DeepLink == "" {
+			return errors.New("设备规则必须至少指定url或deepLink")
+		}
+		if rule.DeepLink != "" {
+			if err := utils.ValidateDeepLink(rule.DeepLink, nil); err != nil {
+				return err
+			}
+		}
+		if rule.FallbackTimeout < 0 {
+			return errors.New("fallbackTimeout不能为负数")
+		}
+	}
+	return nil
+}
+
+// Match 返回与指定平台匹配的规则
+func (r DeviceRules) Match(platform string) (*DeviceRule, bool) {
+	for i := range r {
+		if r[i].Platform == platform {
+			return &r[i], true
+		}
+	}
+	return nil, false
+}
+
+// PolicyTarget 设备规则中需要按域名策略检查的地址，Field为地址所在的字段
+type PolicyTarget struct {
+	Field string
+	URL   string
+}
+
+// PolicyTargets 返回规则中需要按域名策略检查的地址：网页地址、深度链接，以及intent://链接的回退网页地址
+// 创建、编辑和定时扫描使用同一份地址列表
+func (rule *DeviceRule) PolicyTargets() []PolicyTarget {
+	var targets []PolicyTarget
+	if rule.URL != "" {
+		targets = append(targets, PolicyTarget{Field: "url", URL: rule.URL})
+	}
+	if rule.DeepLink != "" {
+		targets = append(targets, PolicyTarget{Field: "deepLink", URL: rule.DeepLink})
+		if fallback := utils.IntentFallbackURL(rule.DeepLink); fallback != "" {
+			targets = append(targets, PolicyTarget{Field: "deepLink", URL: fallback})
+		}
+	}
+	return targets
+}
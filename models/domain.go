@@ -0,0 +1,133 @@
+package models
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDomainID 默认域名的ID，对应配置文件中的访问服务BaseURL
+const DefaultDomainID int64 = 0
+
+// domainReloadInterval 域名列表的刷新间隔
+const domainReloadInterval = time.Minute
+
+// Domain 是短链接域名的模型，不同域名下的短码互不冲突
+type Domain struct {
+	ID          int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	Host        string `gorm:"uniqueIndex;type:varchar(255);not null" json:"host"`
+	BaseURL     string `gorm:"type:varchar(255);not null" json:"baseUrl"`
+	FallbackURL string `gorm:"type:varchar(1000)" json:"fallbackUrl"`
+	// WorkspaceID 所属工作区，只有该工作区可以在此域名下创建短链接；默认域名所有工作区共用
+	WorkspaceID int64     `gorm:"index;default:0" json:"workspaceId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TableName 设置表名
+func (Domain) TableName() string {
+	return "domains"
+}
+
+// DomainRequest 创建或编辑域名的请求
+type DomainRequest struct {
+	Host        string `json:"host" binding:"required,max=255"`
+	BaseURL     string `json:"baseUrl" binding:"required,url,max=255"`
+	FallbackURL string `json:"fallbackUrl" binding:"omitempty,url,max=1000"`
+}
+
+// NormalizeHost 规范化Host，去掉端口并转为小写
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	return host
+}
+
+// DomainRegistry 缓存域名列表，按Host解析访问请求所属的域名
+type DomainRegistry struct {
+	db       *gorm.DB
+	domains  map[string]*Domain
+	loadedAt time.Time
+	mutex    sync.RWMutex
+	// reloading 是否正在后台刷新，同一时间只允许一个刷新
+	reloading atomic.Bool
+}
+
+// NewDomainRegistry 创建新的域名注册表
+func NewDomainRegistry(db *gorm.DB) *DomainRegistry {
+	return &DomainRegistry{
+		db:      db,
+		domains: make(map[string]*Domain),
+	}
+}
+
+// Reload 从数据库重新加载域名列表
+func (r *DomainRegistry) Reload() error {
+	var list []Domain
+	if err := r.db.Find(&list).Error; err != nil {
+		return err
+	}
+
+	domains := make(map[string]*Domain, len(list))
+	for i := range list {
+		domains[NormalizeHost(list[i].Host)] = &list[i]
+	}
+
+	r.mutex.Lock()
+	r.domains = domains
+	r.loadedAt = time.Now()
+	r.mutex.Unlock()
+	return nil
+}
+
+// refreshIfStale 定期刷新域名列表，使其他实例上的修改也能生效
+// 刷新在后台进行，期间请求继续使用当前列表；刷新失败时保留当前列表，等下一个刷新间隔再重试，
+// 避免数据库不可用时每次跳转都查询数据库
+func (r *DomainRegistry) refreshIfStale() {
+	r.mutex.RLock()
+	stale := time.Since(r.loadedAt) > domainReloadInterval
+	r.mutex.RUnlock()
+
+	if !stale || !r.reloading.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer r.reloading.Store(false)
+		if err := r.Reload(); err != nil {
+			log.Printf("刷新域名列表失败: %v", err)
+			r.mutex.Lock()
+			r.loadedAt = time.Now()
+			r.mutex.Unlock()
+		}
+	}()
+}
+
+// Resolve 根据Host查找域名，未登记的Host返回false
+func (r *DomainRegistry) Resolve(host string) (*Domain, bool) {
+	r.refreshIfStale()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	domain, ok := r.domains[NormalizeHost(host)]
+	return domain, ok
+}
+
+// BaseURLs 返回域名ID到BaseURL的映射，用于构建完整短链接
+func (r *DomainRegistry) BaseURLs() map[int64]string {
+	r.refreshIfStale()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	baseURLs := make(map[int64]string, len(r.domains))
+	for _, domain := range r.domains {
+		baseURLs[domain.ID] = domain.BaseURL
+	}
+	return baseURLs
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// 域名策略动作
+const (
+	PolicyActionAllow = "allow"
+	PolicyActionDeny  = "deny"
+)
+
+// DomainPolicy 是管理员维护的目标域名策略
+// Pattern 为域名（精确匹配）或 *.example.com 形式的通配符（匹配所有子域名）
+type DomainPolicy struct {
+	ID        int64     `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	Pattern   string    `gorm:"uniqueIndex;type:varchar(255);not null" json:"pattern"`
+	Action    string    `gorm:"type:varchar(8);not null" json:"action"`
+	Reason    string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedBy string    `gorm:"type:varchar(50)" json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 设置表名
+func (DomainPolicy) TableName() string {
+	return "domain_policies"
+}
+
+// DomainPolicyRequest 添加域名策略的请求
+type DomainPolicyRequest struct {
+	Pattern string `json:"pattern" binding:"required,max=255"`
+	Action  string `json:"action" binding:"required,oneof=allow deny"`
+	Reason  string `json:"reason" binding:"max=255"`
+}
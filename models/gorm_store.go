@@ -11,8 +11,9 @@ import (
 
 // GormStore 使用GORM和Redis ID生成器的存储实现
 type GormStore struct {
-	db    *gorm.DB
-	cache *LRUCache
+	db      *gorm.DB
+	cache   *LRUCache
+	domains *DomainRegistry
 }
 
 // NewGormStore 创建新的GORM存储
@@ -31,13 +32,20 @@ func NewGormStore(dsn string, cacheSize int, idGenerator *utils.RedisIDGenerator
 	}
 
 	// 自动迁移表结构
-	if err := db.AutoMigrate(&DBShortLink{}); err != nil {
+	if err := migrateShortLinks(db); err != nil {
+		return nil, err
+	}
+
+	// 加载域名列表
+	domains := NewDomainRegistry(db)
+	if err := domains.Reload(); err != nil {
 		return nil, err
 	}
 
 	return &GormStore{
-		db:    db,
-		cache: NewLRUCache(cacheSize),
+		db:      db,
+		cache:   NewLRUCache(cacheSize),
+		domains: domains,
 	}, nil
 }
 
@@ -53,58 +61,92 @@ func (s *GormStore) Save(shortLink *ShortLink) error {
 	shortLink.ID = dbLink.ID
 
 	// 保存到缓存
-	s.cache.Put(shortLink.ShortCode, shortLink)
+	s.cache.Put(cacheKey(shortLink.DomainID, shortLink.ShortCode), shortLink)
 	return nil
 }
 
-// Get 根据短码获取短链接
-func (s *GormStore) Get(shortCode string) (*ShortLink, error) {
+// Get 根据域名和短码获取短链接，并异步更新访问计数
+func (s *GormStore) Get(domainID int64, shortCode string) (*ShortLink, error) {
+	link, err := s.Lookup(domainID, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	go s.updateAccessCount(link.ID)
+	return link, nil
+}
+
+// Lookup 根据域名和短码查询短链接，不更新访问计数
+func (s *GormStore) Lookup(domainID int64, shortCode string) (*ShortLink, error) {
 	// 先从缓存获取
-	if link, found := s.cache.Get(shortCode); found {
+	if link, found := s.cache.Get(cacheKey(domainID, shortCode)); found {
 		// 检查链接是否过期
 		if time.Now().After(link.ExpiresAt) {
-			return nil, ErrLinkNotFound
+			return nil, ErrLinkExpired
 		}
 
-		// 异步更新访问计数
-		go s.updateAccessCount(shortCode)
+		// 检查链接是否已停用或隔离
+		if err := link.StatusError(); err != nil {
+			return nil, err
+		}
 
 		return link, nil
 	}
 
 	// 从数据库获取
 	var dbLink DBShortLink
-	if err := s.db.Where("short_code = ?", shortCode).First(&dbLink).Error; err != nil {
+	if err := s.db.Where("domain_id = ? AND short_code = ?", domainID, shortCode).First(&dbLink).Error; err != nil {
 		return nil, ErrLinkNotFound
 	}
 
 	// 检查链接是否过期
 	if time.Now().After(dbLink.ExpiresAt) {
-		return nil, ErrLinkNotFound
+		return nil, ErrLinkExpired
 	}
 
 	// 转换为ShortLink
 	link := dbLink.ToShortLink()
 
-	// 添加到缓存
-	s.cache.Put(shortCode, link)
+	// 检查链接是否已停用或隔离
+	if err := link.StatusError(); err != nil {
+		return nil, err
+	}
+
+	// 加载分流目标
+	if variants, err := loadVariants(s.db, link.ID); err == nil {
+		link.Variants = variants
+	}
 
-	// 异步更新访问计数
-	go s.updateAccessCount(shortCode)
+	// 添加到缓存
+	s.cache.Put(cacheKey(domainID, shortCode), link)
 
 	return link, nil
 }
 
 // updateAccessCount 更新访问计数
-func (s *GormStore) updateAccessCount(shortCode string) {
+func (s *GormStore) updateAccessCount(id int64) {
 	s.db.Model(&DBShortLink{}).
-		Where("short_code = ?", shortCode).
+		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"access_count": gorm.Expr("access_count + 1"),
 			"last_access":  time.Now(),
 		})
 }
 
+// RecordClick 异步记录点击事件
+func (s *GormStore) RecordClick(click *ShortLinkClick) {
+	go s.db.Create(click)
+}
+
+// ReportLink 保存举报，达到阈值时自动隔离短链接
+func (s *GormStore) ReportLink(link *ShortLink, report *LinkReport, threshold int) (bool, error) {
+	return reportLink(s.db, s.cache, link, report, threshold)
+}
+
+// ResolveDomain 根据Host查找域名
+func (s *GormStore) ResolveDomain(host string) (*Domain, bool) {
+	return s.domains.Resolve(host)
+}
+
 // Close 关闭数据库连接
 func (s *GormStore) Close() error {
 	sqlDB, err := s.db.DB()
@@ -120,7 +162,12 @@ func (s *GormStore) GetDB() *gorm.DB {
 }
 
 // RemoveFromCache 从缓存中删除短链接
-func (s *GormStore) RemoveFromCache(shortCode string) {
+func (s *GormStore) RemoveFromCache(domainID int64, shortCode string) {
 	// 从缓存中删除
-	s.cache.Remove(shortCode)
+	s.cache.Remove(cacheKey(domainID, shortCode))
+}
+
+// Domains 获取域名注册表
+func (s *GormStore) Domains() *DomainRegistry {
+	return s.domains
 }
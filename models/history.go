@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// EnsureHistoryTable 确保历史表存在，并补齐短链接表后续新增的字段
+// 历史表按月使用 CREATE TABLE ... LIKE 创建，短链接表新增字段后旧的历史表需要同步结构
+func EnsureHistoryTable(db *gorm.DB, historyTable string) error {
+	if err := db.Exec("CREATE TABLE IF NOT EXISTS " + historyTable + " LIKE short_links").Error; err != nil {
+		return err
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&DBShortLink{}); err != nil {
+		return err
+	}
+
+	migrator := db.Table(historyTable).Migrator()
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" || migrator.HasColumn(&DBShortLink{}, field.DBName) {
+			continue
+		}
+		if err := migrator.AddColumn(&DBShortLink{}, field.Name); err != nil {
+			return err
+		}
+	}
+
+	// 旧的历史表复制了短码的全局唯一索引，不同域名的相同短码需要能同时归档
+	return dropLegacyShortCodeIndex(migrator)
+}
+
+// MigrateHistoryTables 同步所有已存在的历史表结构，保证按新增字段筛选历史短链接时不会出错
+func MigrateHistoryTables(db *gorm.DB, prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+
+	var tables []string
+	if err := db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name LIKE ?",
+		prefix+"%").Scan(&tables).Error; err != nil {
+		return err
+	}
+	for _, table := range tables {
+		// LIKE中的下划线会匹配任意字符，需要再精确比较前缀
+		if !strings.HasPrefix(table, prefix) {
+			continue
+		}
+		if err := EnsureHistoryTable(db, table); err != nil {
+			return fmt.Errorf("同步历史表%s结构失败: %v", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package models
+
+// 短链接状态
+const (
+	LinkStatusActive      = "active"      // 正常
+	LinkStatusDisabled    = "disabled"    // 已停用
+	LinkStatusQuarantined = "quarantined" // 因疑似滥用被隔离，等待审核
+)
+
+// IsValidLinkStatus 检查短链接状态是否有效
+func IsValidLinkStatus(status string) bool {
+	switch status {
+	case LinkStatusActive, LinkStatusDisabled, LinkStatusQuarantined:
+		return true
+	}
+	return false
+}
+
+// StatusError 返回短链接状态对应的访问错误，可访问时返回nil，未设置状态的旧数据视为可访问
+func (sl *ShortLink) StatusError() error {
+	switch sl.Status {
+	case "", LinkStatusActive:
+		return nil
+	case LinkStatusQuarantined:
+		return ErrLinkQuarantined
+	}
+	return ErrLinkDisabled
+}
+
+// UpdateLinkStatusRequest 修改短链接状态的请求
+type UpdateLinkStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active disabled quarantined"`
+	Reason string `json:"reason" binding:"max=255"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 登录审计结果
+const (
+	LoginResultSuccess       = "success"        // 登录成功
+	LoginResultBadPassword   = "bad_password"   // 密码错误
+	LoginResultBadTwoFactor  = "bad_2fa"        // 两步验证码错误
+	LoginResultUnknownUser   = "unknown_user"   // 用户名不存在
+	LoginResultLocked        = "locked"         // 账户已锁定
+	LoginResultThrottled     = "throttled"      // 失败次数过多，需要等待
+	LoginResultIPBlocked     = "ip_blocked"     // 来源IP失败次数过多
+	LoginResultAccountLocked = "account_locked" // 本次失败导致账户被锁定
+)
+
+// LoginAudit 登录审计记录
+type LoginAudit struct {
+	ID        int64     `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	AdminID   int64     `gorm:"index" json:"adminId"`
+	Username  string    `gorm:"type:varchar(50);index" json:"username"`
+	IP        string    `gorm:"type:varchar(64);index:idx_login_audit_ip_time,priority:1" json:"ip"`
+	UserAgent string    `gorm:"type:varchar(255)" json:"userAgent"`
+	Result    string    `gorm:"type:varchar(20);not null" json:"result"`
+	Success   bool      `gorm:"not null" json:"success"`
+	CreatedAt time.Time `gorm:"type:datetime;not null;index:idx_login_audit_ip_time,priority:2" json:"createdAt"`
+}
+
+// TableName 设置表名
+func (LoginAudit) TableName() string {
+	return "sys_login_audit"
+}
+
+// CountIPLoginFailures 统计IP在指定时间之后的登录失败次数
+func CountIPLoginFailures(db *gorm.DB, ip string, since time.Time) int64 {
+	var count int64
+	db.Model(&LoginAudit{}).
+		Where("ip = ? AND success = ? AND created_at > ?", ip, false, since).
+		Count(&count)
+	return count
+}
@@ -0,0 +1,20 @@
+package models
+
+// 跳转方式
+const (
+	RedirectMovedPermanently = "301"
+	RedirectFound            = "302"
+	RedirectTemporary        = "307"
+	RedirectPermanent        = "308"
+	RedirectMeta             = "meta" // 返回HTML页面，通过meta refresh和JavaScript跳转
+	DefaultRedirectType      = RedirectTemporary
+)
+
+// IsValidRedirectType 检查跳转方式是否有效，空字符串表示使用全局默认值
+func IsValidRedirectType(redirectType string) bool {
+	switch redirectType {
+	case "", RedirectMovedPermanently, RedirectFound, RedirectTemporary, RedirectPermanent, RedirectMeta:
+		return true
+	}
+	return false
+}
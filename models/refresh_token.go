@@ -0,0 +1,107 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRefreshToken 刷新令牌无效、已过期或已吊销
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效")
+	// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，可能已泄露
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用")
+)
+
+// RefreshToken 服务端保存的刷新令牌，只保存SHA-256哈希
+// 每次刷新都会签发新的刷新令牌并使旧令牌失效，同一登录会话中的令牌共享SessionID
+type RefreshToken struct {
+	ID        int64      `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
+	AdminID   int64      `gorm:"index;not null"`
+	SessionID string     `gorm:"type:varchar(32);index;not null"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	IP        string     `gorm:"type:varchar(64)"`
+	UserAgent string     `gorm:"type:varchar(255)"`
+	ExpiresAt time.Time  `gorm:"type:datetime;not null"`
+	RotatedAt *time.Time `gorm:"type:datetime"`
+	RevokedAt *time.Time `gorm:"type:datetime"`
+	CreatedAt time.Time  `gorm:"type:datetime;not null"`
+}
+
+// TableName 设置表名
+func (RefreshToken) TableName() string {
+	return "sys_refresh_tokens"
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// hashRefreshToken 计算刷新令牌的哈希
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueRefreshToken 为登录会话签发刷新令牌，返回令牌明文
+func IssueRefreshToken(db *gorm.DB, record *RefreshToken, lifetime time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	record.TokenHash = hashRefreshToken(token)
+	record.CreatedAt = time.Now()
+	record.ExpiresAt = record.CreatedAt.Add(lifetime)
+	if err := db.Create(record).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RotateRefreshToken 使用刷新令牌，成功时标记为已轮换并返回令牌记录
+// 已轮换的令牌再次使用时视为泄露，返回令牌记录和ErrRefreshTokenReused，由调用方吊销整个会话
+func RotateRefreshToken(db *gorm.DB, token string) (*RefreshToken, error) {
+	var record RefreshToken
+	if err := db.Where("token_hash = ?", hashRefreshToken(token)).First(&record).Error; err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if record.RevokedAt != nil || time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if record.RotatedAt != nil {
+		return &record, ErrRefreshTokenReused
+	}
+
+	// 条件更新，并发使用同一令牌时只有一个请求成功
+	result := db.Model(&RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", record.ID).
+		Update("rotated_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &record, ErrRefreshTokenReused
+	}
+	return &record, nil
+}
+
+// RevokeRefreshSession 吊销登录会话中的所有刷新令牌
+func RevokeRefreshSession(db *gorm.DB, sessionID string) error {
+	return db.Model(&RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAdminRefreshTokens 吊销管理员的所有刷新令牌
+func RevokeAdminRefreshTokens(db *gorm.DB, adminID int64) error {
+	return db.Model(&RefreshToken{}).
+		Where("admin_id = ? AND revoked_at IS NULL", adminID).
+		Update("revoked_at", time.Now()).Error
+}
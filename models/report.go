@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 举报处理状态
+const (
+	ReportStatusPending   = "pending"   // 待处理
+	ReportStatusDismissed = "dismissed" // 已驳回
+	ReportStatusActioned  = "actioned"  // 已处理（短链接已停用）
+)
+
+// LinkReport 是访问者对短链接的举报
+type LinkReport struct {
+	ID         int64      `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	LinkID     int64      `gorm:"index;not null" json:"linkId"`
+	ShortCode  string     `gorm:"type:varchar(16)" json:"shortCode"`
+	Reason     string     `gorm:"type:varchar(500);not null" json:"reason"`
+	Contact    string     `gorm:"type:varchar(255)" json:"contact"`
+	ReporterIP string     `gorm:"type:varchar(64);index" json:"reporterIp"`
+	Status     string     `gorm:"type:varchar(16);default:pending;index" json:"status"`
+	HandledBy  string     `gorm:"type:varchar(50)" json:"handledBy"`
+	HandledAt  *time.Time `json:"handledAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// TableName 设置表名
+func (LinkReport) TableName() string {
+	return "link_reports"
+}
+
+// ReportLinkRequest 举报短链接的请求
+type ReportLinkRequest struct {
+	Reason  string `json:"reason" form:"reason" binding:"required,max=500"`
+	Contact string `json:"contact" form:"contact" binding:"max=255"`
+}
+
+// reportLink 保存举报，不同举报人数达到阈值时自动隔离短链接
+// 同一IP对同一短链接只保留一条待处理的举报；threshold小于等于0时不自动隔离
+func reportLink(db *gorm.DB, cache *LRUCache, link *ShortLink, report *LinkReport, threshold int) (bool, error) {
+	var count int64
+	db.Model(&LinkReport{}).
+		Where("link_id = ? AND reporter_ip = ? AND status = ?", link.ID, report.ReporterIP, ReportStatusPending).
+		Count(&count)
+	if count == 0 {
+		if err := db.Create(report).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if link.ReportThreshold > 0 {
+		threshold = link.ReportThreshold
+	}
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	// 统计未被驳回的举报中不同举报人的数量
+	var reporters int64
+	if err := db.Model(&LinkReport{}).
+		Where("link_id = ? AND status <> ?", link.ID, ReportStatusDismissed).
+		Distinct("reporter_ip").
+		Count(&reporters).Error; err != nil {
+		return false, err
+	}
+	if reporters < int64(threshold) {
+		return false, nil
+	}
+
+	result := db.Model(&DBShortLink{}).
+		Where("id = ? AND status = ?", link.ID, LinkStatusActive).
+		Updates(map[string]interface{}{
+			"status":            LinkStatusQuarantined,
+			"status_reason":     fmt.Sprintf("被%d位访问者举报，已自动隔离", reporters),
+			"status_updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	cache.Remove(cacheKey(link.DomainID, link.ShortCode))
+	return result.RowsAffected > 0, nil
+}
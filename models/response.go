@@ -8,14 +8,31 @@ import (
 
 // FormattedShortLink 格式化后的短链接响应结构
 type FormattedShortLink struct {
-	ID          int64  `json:"id"`
-	ShortCode   string `json:"shortCode"`
-	ShortLink   string `json:"shortLink"`
-	OriginalURL string `json:"originalUrl"`
-	CreatedAt   string `json:"createdAt"`
-	ExpiresAt   string `json:"expiresAt"`
-	AccessCount int64  `json:"accessCount"`
-	LastAccess  string `json:"lastAccess"`
+	ID               int64       `json:"id"`
+	DomainID         int64       `json:"domainId"`
+	ShortCode        string      `json:"shortCode"`
+	ShortLink        string      `json:"shortLink"`
+	OriginalURL      string      `json:"originalUrl"`
+	CreatedAt        string      `json:"createdAt"`
+	ExpiresAt        string      `json:"expiresAt"`
+	AccessCount      int64       `json:"accessCount"`
+	LastAccess       string      `json:"lastAccess"`
+	DeviceRules      DeviceRules `json:"deviceRules"`
+	UTMParams        UTMParams   `json:"utmParams"`
+	QueryPassthrough bool        `json:"queryPassthrough"`
+	PrefixMode       bool        `json:"prefixMode"`
+	RedirectType     string      `json:"redirectType"`
+	ForcePreview     bool        `json:"forcePreview"`
+	OGTitle          string      `json:"ogTitle"`
+	OGDescription    string      `json:"ogDescription"`
+	OGImage          string      `json:"ogImage"`
+	Status           string      `json:"status"`
+	StatusReason     string      `json:"statusReason"`
+	StatusUpdatedAt  string      `json:"statusUpdatedAt"`
+	ReportThreshold  int         `json:"reportThreshold"`
+	WorkspaceID      int64       `json:"workspaceId"`
+	CreatedBy        int64       `json:"createdBy"`
+	APIKeyID         int64       `json:"apiKeyId"`
 }
 
 // FormatTime 将时间格式化为指定格式
@@ -27,16 +44,36 @@ func FormatTime(t time.Time) string {
 }
 
 // ToFormattedShortLink 将DBShortLink转换为FormattedShortLink
-// baseURL 是访问API服务的BaseURL，用于构建完整的短链接URL
-func (db *DBShortLink) ToFormattedShortLink(baseURL string) FormattedShortLink {
-	return FormattedShortLink{
-		ID:          db.ID,
-		ShortCode:   db.ShortCode,
-		ShortLink:   utils.BuildShortLink(baseURL, db.ShortCode),
-		OriginalURL: db.OriginalURL,
-		CreatedAt:   FormatTime(db.CreatedAt),
-		ExpiresAt:   FormatTime(db.ExpiresAt),
-		AccessCount: db.AccessCount,
-		LastAccess:  FormatTime(db.LastAccess),
+// baseURL 是短链接所属域名的BaseURL，pathPrefix 是访问服务的短链接路径前缀，用于构建完整的短链接URL
+func (db *DBShortLink) ToFormattedShortLink(baseURL, pathPrefix string) FormattedShortLink {
+	formatted := FormattedShortLink{
+		ID:               db.ID,
+		DomainID:         db.DomainID,
+		ShortCode:        db.ShortCode,
+		ShortLink:        utils.BuildShortLink(baseURL, pathPrefix, db.ShortCode),
+		OriginalURL:      db.OriginalURL,
+		CreatedAt:        FormatTime(db.CreatedAt),
+		ExpiresAt:        FormatTime(db.ExpiresAt),
+		AccessCount:      db.AccessCount,
+		LastAccess:       FormatTime(db.LastAccess),
+		DeviceRules:      db.DeviceRules,
+		UTMParams:        db.UTMParams,
+		QueryPassthrough: db.QueryPassthrough,
+		PrefixMode:       db.PrefixMode,
+		RedirectType:     db.RedirectType,
+		ForcePreview:     db.ForcePreview,
+		OGTitle:          db.OGTitle,
+		OGDescription:    db.OGDescription,
+		OGImage:          db.OGImage,
+		Status:           db.Status,
+		StatusReason:     db.StatusReason,
+		ReportThreshold:  db.ReportThreshold,
+		WorkspaceID:      db.WorkspaceID,
+		CreatedBy:        db.CreatedBy,
+		APIKeyID:         db.APIKeyID,
 	}
+	if db.StatusUpdatedAt != nil {
+		formatted.StatusUpdatedAt = FormatTime(*db.StatusUpdatedAt)
+	}
+	return formatted
 }
@@ -0,0 +1,75 @@
+package models
+
+import "gorm.io/gorm"
+
+// 管理员角色，权限从高到低依次为 owner、admin、editor、viewer
+// owner: 全部权限，可以管理所有管理员账户
+// admin: 管理域名、域名策略、API密钥和编辑及以下角色的账户
+// editor: 创建、编辑、删除短链接，处理举报
+// viewer: 只能浏览短链接和统计数据
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// roleRanks 角色等级，数值越大权限越高
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// IsValidRole 检查角色是否有效
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast 检查角色是否不低于指定角色，无效角色没有任何权限
+func RoleAtLeast(role, min string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[min]
+}
+
+// CanManageRole 检查actor角色能否管理target角色的账户，或将账户设为target角色
+// owner可以管理所有角色，其他角色只能管理比自己低的角色
+func CanManageRole(actor, target string) bool {
+	if actor == RoleOwner {
+		return IsValidRole(target)
+	}
+	return RoleAtLeast(actor, RoleAdmin) && roleRanks[actor] > roleRanks[target] && IsValidRole(target)
+}
+
+// CanViewAllLinks 检查角色能否浏览所有短链接，viewer及以上都可以浏览工作区内的所有短链接
+func CanViewAllLinks(role string) bool {
+	return RoleAtLeast(role, RoleViewer)
+}
+
+// CanManageAllLinks 检查角色能否修改所有短链接，否则只能修改自己创建的短链接
+// admin及以上可以管理所有短链接；editor只能编辑、停用、删除自己创建的短链接
+func CanManageAllLinks(role string) bool {
+	return RoleAtLeast(role, RoleAdmin)
+}
+
+// VisibleLinks 按角色限定短链接的浏览范围
+func VisibleLinks(role string, userID int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if CanViewAllLinks(role) {
+			return db
+		}
+		return db.Where("created_by = ?", userID)
+	}
+}
+
+// OwnedLinks 按角色限定短链接的修改范围，没有全局管理权限的角色只能修改自己创建的短链接
+func OwnedLinks(role string, userID int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if CanManageAllLinks(role) {
+			return db
+		}
+		return db.Where("created_by = ?", userID)
+	}
+}
@@ -6,17 +6,74 @@ import (
 
 // ShortLink 表示短链接的数据结构
 type ShortLink struct {
-	ID          int64     `json:"id"`
-	OriginalURL string    `json:"originalUrl"`
-	ShortCode   string    `json:"shortCode"`
-	CreatedAt   time.Time `json:"createdAt"`
-	ExpiresAt   time.Time `json:"expiresAt"`
+	ID          int64              `json:"id"`
+	DomainID    int64              `json:"domainId"`
+	OriginalURL string             `json:"originalUrl"`
+	ShortCode   string             `json:"shortCode"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	ExpiresAt   time.Time          `json:"expiresAt"`
+	DeviceRules DeviceRules        `json:"deviceRules,omitempty"`
+	Variants    []ShortLinkVariant `json:"variants,omitempty"`
+	UTMParams   UTMParams          `json:"utmParams"`
+	// QueryPassthrough 为true时，将访问请求的查询参数合并到目标URL
+	QueryPassthrough bool `json:"queryPassthrough"`
+	// PrefixMode 为true时，短码之后的路径会追加到目标URL
+	PrefixMode bool `json:"prefixMode"`
+	// RedirectType 跳转方式，为空时使用全局默认值
+	RedirectType string `json:"redirectType"`
+	// ForcePreview 为true时，访问者总是先看到预览页，确认后再跳转
+	ForcePreview bool `json:"forcePreview"`
+	// 社交分享卡片信息，向预览爬虫展示
+	OGTitle       string `json:"ogTitle"`
+	OGDescription string `json:"ogDescription"`
+	OGImage       string `json:"ogImage"`
+	// Status 短链接状态，非active的短链接不可访问
+	Status       string `json:"status"`
+	StatusReason string `json:"statusReason"`
+	// ReportThreshold 自动隔离所需的不同举报人数，为0时使用全局配置
+	ReportThreshold int `json:"reportThreshold"`
+	// WorkspaceID 所属工作区
+	WorkspaceID int64 `json:"workspaceId"`
+	// CreatedBy 创建者管理员ID，APIKeyID 创建时使用的API密钥ID
+	CreatedBy int64 `json:"createdBy"`
+	APIKeyID  int64 `json:"apiKeyId"`
+}
+
+// HasSocialCard 检查是否设置了社交分享卡片信息
+func (sl *ShortLink) HasSocialCard() bool {
+	return sl.OGTitle != "" || sl.OGDescription != "" || sl.OGImage != ""
 }
 
 // CreateShortLinkRequest 创建短链接的请求结构
 type CreateShortLinkRequest struct {
-	Link   string `json:"link" binding:"required"`
-	Expire int    `json:"expire" binding:"required"`
+	Link             string      `json:"link" binding:"required"`
+	Expire           int         `json:"expire" binding:"required"`
+	Domain           string      `json:"domain"` // 短链接使用的域名，为空时使用默认域名
+	DeviceRules      DeviceRules `json:"deviceRules"`
+	UTMParams        UTMParams   `json:"utmParams"`
+	QueryPassthrough bool        `json:"queryPassthrough"`
+	PrefixMode       bool        `json:"prefixMode"`
+	RedirectType     string      `json:"redirectType"`
+	ForcePreview     bool        `json:"forcePreview"`
+	OGTitle          string      `json:"ogTitle" binding:"max=200"`
+	OGDescription    string      `json:"ogDescription" binding:"max=500"`
+	OGImage          string      `json:"ogImage" binding:"omitempty,url,max=1000"`
+}
+
+// UpdateShortLinkRequest 编辑短链接的请求结构，未提供的字段保持不变
+type UpdateShortLinkRequest struct {
+	Link             *string      `json:"link"`
+	Expire           *int         `json:"expire"` // 过期时间（秒），从修改时开始计算
+	DeviceRules      *DeviceRules `json:"deviceRules"`
+	UTMParams        *UTMParams   `json:"utmParams"`
+	QueryPassthrough *bool        `json:"queryPassthrough"`
+	PrefixMode       *bool        `json:"prefixMode"`
+	RedirectType     *string      `json:"redirectType"`
+	ForcePreview     *bool        `json:"forcePreview"`
+	OGTitle          *string      `json:"ogTitle" binding:"omitempty,max=200"`
+	OGDescription    *string      `json:"ogDescription" binding:"omitempty,max=500"`
+	OGImage          *string      `json:"ogImage" binding:"omitempty,max=1000,eq=|url"` // 传空字符串表示清除
+	ReportThreshold  *int         `json:"reportThreshold" binding:"omitempty,min=0"`
 }
 
 // CreateShortLinkResponse 创建短链接的响应结构
@@ -3,6 +3,7 @@ package models
 import (
 	"container/list"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -13,25 +14,75 @@ import (
 )
 
 var (
-	ErrLinkNotFound = errors.New("短链接不存在或已过期")
+	ErrLinkNotFound    = errors.New("短链接不存在或已过期")
+	ErrLinkExpired     = errors.New("短链接已过期")
+	ErrLinkDisabled    = errors.New("短链接已停用")
+	ErrLinkQuarantined = errors.New("短链接已被隔离")
 )
 
 // Store 是短链接存储的接口
 type Store interface {
 	Save(shortLink *ShortLink) error
-	Get(shortCode string) (*ShortLink, error)
+	Get(domainID int64, shortCode string) (*ShortLink, error)
+	Lookup(domainID int64, shortCode string) (*ShortLink, error)
+	ResolveDomain(host string) (*Domain, bool)
+	RecordClick(click *ShortLinkClick)
+	ReportLink(link *ShortLink, report *LinkReport, threshold int) (bool, error)
 	Close() error
 }
 
 // DBShortLink 是数据库中短链接的模型
 type DBShortLink struct {
-	ID          int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
-	ShortCode   string `gorm:"uniqueIndex;type:varchar(16)"`
-	OriginalURL string `gorm:"type:text"`
-	CreatedAt   time.Time
-	ExpiresAt   time.Time
-	AccessCount int64 `gorm:"default:0"`
-	LastAccess  time.Time
+	ID               int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
+	DomainID         int64  `gorm:"uniqueIndex:idx_short_links_domain_code,priority:1;default:0"`
+	ShortCode        string `gorm:"uniqueIndex:idx_short_links_domain_code,priority:2;type:varchar(16)"`
+	OriginalURL      string `gorm:"type:text"`
+	CreatedAt        time.Time
+	ExpiresAt        time.Time
+	AccessCount      int64 `gorm:"default:0"`
+	LastAccess       time.Time
+	DeviceRules      DeviceRules `gorm:"type:text"`
+	UTMParams        UTMParams   `gorm:"type:text"`
+	QueryPassthrough bool        `gorm:"default:false"`
+	PrefixMode       bool        `gorm:"default:false"`
+	RedirectType     string      `gorm:"type:varchar(8)"`
+	ForcePreview     bool        `gorm:"default:false"`
+	OGTitle          string      `gorm:"type:varchar(200)"`
+	OGDescription    string      `gorm:"type:varchar(500)"`
+	OGImage          string      `gorm:"type:varchar(1000)"`
+	Status           string      `gorm:"type:varchar(16);default:active;index"`
+	StatusReason     string      `gorm:"type:varchar(255)"`
+	StatusUpdatedAt  *time.Time
+	ReportThreshold  int `gorm:"default:0"`
+	// WorkspaceID 所属工作区
+	WorkspaceID int64 `gorm:"index;default:0"`
+	// CreatedBy 创建短链接的管理员ID，APIKeyID 创建短链接的API密钥ID，匿名创建时都为0
+	CreatedBy int64 `gorm:"index;default:0"`
+	APIKeyID  int64 `gorm:"index;default:0"`
+}
+
+// legacyShortCodeIndex 短码全局唯一时使用的索引，改为按域名唯一后需要删除
+const legacyShortCodeIndex = "idx_short_links_short_code"
+
+// migrateShortLinks 迁移短链接相关的表结构
+func migrateShortLinks(db *gorm.DB) error {
+	if err := db.AutoMigrate(&DBShortLink{}, &Domain{}, &DomainPolicy{}, &ShortLinkVariant{}, &ShortLinkClick{}, &LinkReport{}); err != nil {
+		return err
+	}
+	return dropLegacyShortCodeIndex(db.Migrator())
+}
+
+// dropLegacyShortCodeIndex 删除短码的全局唯一索引
+func dropLegacyShortCodeIndex(migrator gorm.Migrator) error {
+	if migrator.HasIndex(&DBShortLink{}, legacyShortCodeIndex) {
+		return migrator.DropIndex(&DBShortLink{}, legacyShortCodeIndex)
+	}
+	return nil
+}
+
+// cacheKey 返回短链接在缓存中的键，短码只在域名内唯一
+func cacheKey(domainID int64, shortCode string) string {
+	return fmt.Sprintf("%d:%s", domainID, shortCode)
 }
 
 // TableName 设置表名
@@ -42,23 +93,55 @@ func (DBShortLink) TableName() string {
 // ToShortLink 转换为ShortLink模型
 func (db *DBShortLink) ToShortLink() *ShortLink {
 	return &ShortLink{
-		ID:          db.ID,
-		ShortCode:   db.ShortCode,
-		OriginalURL: db.OriginalURL,
-		CreatedAt:   db.CreatedAt,
-		ExpiresAt:   db.ExpiresAt,
+		ID:               db.ID,
+		DomainID:         db.DomainID,
+		ShortCode:        db.ShortCode,
+		OriginalURL:      db.OriginalURL,
+		CreatedAt:        db.CreatedAt,
+		ExpiresAt:        db.ExpiresAt,
+		DeviceRules:      db.DeviceRules,
+		UTMParams:        db.UTMParams,
+		QueryPassthrough: db.QueryPassthrough,
+		PrefixMode:       db.PrefixMode,
+		RedirectType:     db.RedirectType,
+		ForcePreview:     db.ForcePreview,
+		OGTitle:          db.OGTitle,
+		OGDescription:    db.OGDescription,
+		OGImage:          db.OGImage,
+		Status:           db.Status,
+		StatusReason:     db.StatusReason,
+		ReportThreshold:  db.ReportThreshold,
+		WorkspaceID:      db.WorkspaceID,
+		CreatedBy:        db.CreatedBy,
+		APIKeyID:         db.APIKeyID,
 	}
 }
 
 // FromShortLink 从ShortLink模型转换
 func FromShortLink(sl *ShortLink) *DBShortLink {
 	return &DBShortLink{
-		ID:          sl.ID,
-		ShortCode:   sl.ShortCode,
-		OriginalURL: sl.OriginalURL,
-		CreatedAt:   sl.CreatedAt,
-		ExpiresAt:   sl.ExpiresAt,
-		LastAccess:  time.Now(),
+		ID:               sl.ID,
+		DomainID:         sl.DomainID,
+		ShortCode:        sl.ShortCode,
+		OriginalURL:      sl.OriginalURL,
+		CreatedAt:        sl.CreatedAt,
+		ExpiresAt:        sl.ExpiresAt,
+		LastAccess:       time.Now(),
+		DeviceRules:      sl.DeviceRules,
+		UTMParams:        sl.UTMParams,
+		QueryPassthrough: sl.QueryPassthrough,
+		PrefixMode:       sl.PrefixMode,
+		RedirectType:     sl.RedirectType,
+		ForcePreview:     sl.ForcePreview,
+		OGTitle:          sl.OGTitle,
+		OGDescription:    sl.OGDescription,
+		OGImage:          sl.OGImage,
+		Status:           sl.Status,
+		StatusReason:     sl.StatusReason,
+		ReportThreshold:  sl.ReportThreshold,
+		WorkspaceID:      sl.WorkspaceID,
+		CreatedBy:        sl.CreatedBy,
+		APIKeyID:         sl.APIKeyID,
 	}
 }
 
@@ -137,6 +220,7 @@ func (c *LRUCache) Remove(key string) {
 type HybridStore struct {
 	db          *gorm.DB
 	cache       *LRUCache
+	domains     *DomainRegistry
 	idGenerator *utils.IDGenerator
 }
 
@@ -151,13 +235,20 @@ func NewHybridStore(dsn string, cacheSize int, idGenerator *utils.IDGenerator) (
 	}
 
 	// 自动迁移表结构
-	if err := db.AutoMigrate(&DBShortLink{}); err != nil {
+	if err := migrateShortLinks(db); err != nil {
+		return nil, err
+	}
+
+	// 加载域名列表
+	domains := NewDomainRegistry(db)
+	if err := domains.Reload(); err != nil {
 		return nil, err
 	}
 
 	return &HybridStore{
 		db:          db,
 		cache:       NewLRUCache(cacheSize),
+		domains:     domains,
 		idGenerator: idGenerator,
 	}, nil
 }
@@ -180,58 +271,108 @@ func (s *HybridStore) Save(shortLink *ShortLink) error {
 	}
 
 	// 保存到缓存
-	s.cache.Put(shortLink.ShortCode, shortLink)
+	s.cache.Put(cacheKey(shortLink.DomainID, shortLink.ShortCode), shortLink)
 	return nil
 }
 
-// Get 根据短码获取短链接
-func (s *HybridStore) Get(shortCode string) (*ShortLink, error) {
+// Get 根据域名和短码获取短链接，并异步更新访问计数
+func (s *HybridStore) Get(domainID int64, shortCode string) (*ShortLink, error) {
+	link, err := s.Lookup(domainID, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	go s.updateAccessCount(link.ID)
+	return link, nil
+}
+
+// Lookup 根据域名和短码查询短链接，不更新访问计数
+func (s *HybridStore) Lookup(domainID int64, shortCode string) (*ShortLink, error) {
 	// 先从缓存获取
-	if link, found := s.cache.Get(shortCode); found {
+	if link, found := s.cache.Get(cacheKey(domainID, shortCode)); found {
 		// 检查链接是否过期
 		if time.Now().After(link.ExpiresAt) {
-			return nil, ErrLinkNotFound
+			return nil, ErrLinkExpired
 		}
 
-		// 异步更新访问计数
-		go s.updateAccessCount(shortCode)
+		// 检查链接是否已停用或隔离
+		if err := link.StatusError(); err != nil {
+			return nil, err
+		}
 
 		return link, nil
 	}
 
 	// 从数据库获取
 	var dbLink DBShortLink
-	if err := s.db.Where("short_code = ?", shortCode).First(&dbLink).Error; err != nil {
+	if err := s.db.Where("domain_id = ? AND short_code = ?", domainID, shortCode).First(&dbLink).Error; err != nil {
 		return nil, ErrLinkNotFound
 	}
 
 	// 检查链接是否过期
 	if time.Now().After(dbLink.ExpiresAt) {
-		return nil, ErrLinkNotFound
+		return nil, ErrLinkExpired
 	}
 
 	// 转换为ShortLink
 	link := dbLink.ToShortLink()
 
-	// 添加到缓存
-	s.cache.Put(shortCode, link)
+	// 检查链接是否已停用或隔离
+	if err := link.StatusError(); err != nil {
+		return nil, err
+	}
 
-	// 异步更新访问计数
-	go s.updateAccessCount(shortCode)
+	// 加载分流目标
+	if variants, err := loadVariants(s.db, link.ID); err == nil {
+		link.Variants = variants
+	}
+
+	// 添加到缓存
+	s.cache.Put(cacheKey(domainID, shortCode), link)
 
 	return link, nil
 }
 
 // updateAccessCount 更新访问计数
-func (s *HybridStore) updateAccessCount(shortCode string) {
+func (s *HybridStore) updateAccessCount(id int64) {
 	s.db.Model(&DBShortLink{}).
-		Where("short_code = ?", shortCode).
+		Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"access_count": gorm.Expr("access_count + 1"),
 			"last_access":  time.Now(),
 		})
 }
 
+// RecordClick 异步记录点击事件
+func (s *HybridStore) RecordClick(click *ShortLinkClick) {
+	go func() {
+		if click.ID == 0 {
+			id, err := s.idGenerator.NextID()
+			if err != nil {
+				return
+			}
+			click.ID = id
+		}
+		s.db.Create(click)
+	}()
+}
+
+// ReportLink 保存举报，达到阈值时自动隔离短链接
+func (s *HybridStore) ReportLink(link *ShortLink, report *LinkReport, threshold int) (bool, error) {
+	if report.ID == 0 {
+		id, err := s.idGenerator.NextID()
+		if err != nil {
+			return false, err
+		}
+		report.ID = id
+	}
+	return reportLink(s.db, s.cache, link, report, threshold)
+}
+
+// ResolveDomain 根据Host查找域名
+func (s *HybridStore) ResolveDomain(host string) (*Domain, bool) {
+	return s.domains.Resolve(host)
+}
+
 // Close 关闭数据库连接
 func (s *HybridStore) Close() error {
 	sqlDB, err := s.db.DB()
@@ -259,28 +400,46 @@ func (s *MemoryStore) Save(shortLink *ShortLink) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.links[shortLink.ShortCode] = shortLink
+	s.links[cacheKey(shortLink.DomainID, shortLink.ShortCode)] = shortLink
 	return nil
 }
 
-// Get 根据短码获取短链接
-func (s *MemoryStore) Get(shortCode string) (*ShortLink, error) {
+// Get 根据域名和短码获取短链接
+func (s *MemoryStore) Get(domainID int64, shortCode string) (*ShortLink, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	link, exists := s.links[shortCode]
+	link, exists := s.links[cacheKey(domainID, shortCode)]
 	if !exists {
 		return nil, ErrLinkNotFound
 	}
 
 	// 检查链接是否过期
 	if time.Now().After(link.ExpiresAt) {
-		return nil, ErrLinkNotFound
+		return nil, ErrLinkExpired
+	}
+
+	// 检查链接是否已停用或隔离
+	if err := link.StatusError(); err != nil {
+		return nil, err
 	}
 
 	return link, nil
 }
 
+// RecordClick 实现Store接口，内存存储不记录点击事件
+func (s *MemoryStore) RecordClick(click *ShortLinkClick) {}
+
+// ReportLink 实现Store接口，内存存储不保存举报
+func (s *MemoryStore) ReportLink(link *ShortLink, report *LinkReport, threshold int) (bool, error) {
+	return false, nil
+}
+
+// ResolveDomain 实现Store接口，内存存储只支持默认域名
+func (s *MemoryStore) ResolveDomain(host string) (*Domain, bool) {
+	return nil, false
+}
+
 // Close 实现Store接口
 func (s *MemoryStore) Close() error {
 	return nil
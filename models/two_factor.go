@@ -0,0 +1,125 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeCount 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// recoveryCodeAlphabet 恢复码使用的字符，去掉了容易混淆的字符
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// AdminRecoveryCode 两步验证的一次性恢复码，只保存哈希
+type AdminRecoveryCode struct {
+	ID        int64      `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
+	AdminID   int64      `gorm:"index;not null"`
+	CodeHash  string     `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `gorm:"type:datetime"`
+	CreatedAt time.Time  `gorm:"type:datetime;not null"`
+}
+
+// TableName 设置表名
+func (AdminRecoveryCode) TableName() string {
+	return "sys_admin_recovery_codes"
+}
+
+// TwoFactorCodeRequest 提交两步验证码的请求，code可以是验证器App中的6位验证码或恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证的请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+// LoginTwoFactorRequest 登录第二步的请求
+type LoginTwoFactorRequest struct {
+	PreAuthToken string `json:"preAuthToken" binding:"required"`
+	Code         string `json:"code" binding:"required,max=32"`
+}
+
+// normalizeRecoveryCode 去掉空白和连字符并转为小写
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// hashRecoveryCode 计算恢复码的哈希
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateRecoveryCode 生成一个 xxxxx-xxxxx 格式的恢复码
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("生成恢复码失败: %v", err)
+		}
+		b[i] = recoveryCodeAlphabet[n.Int64()]
+	}
+	return string(b[:5]) + "-" + string(b[5:]), nil
+}
+
+// ReplaceRecoveryCodes 为管理员生成新的恢复码，之前的恢复码全部失效，返回恢复码明文
+func ReplaceRecoveryCodes(db *gorm.DB, adminID int64) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	records := make([]AdminRecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = AdminRecoveryCode{
+			AdminID:   adminID,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedAt: time.Now(),
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("admin_id = ?", adminID).Delete(&AdminRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode 使用一个未使用的恢复码，成功时返回true
+func UseRecoveryCode(db *gorm.DB, adminID int64, code string) bool {
+	result := db.Model(&AdminRecoveryCode{}).
+		Where("admin_id = ? AND code_hash = ? AND used_at IS NULL", adminID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected > 0
+}
+
+// CountRecoveryCodes 统计管理员剩余可用的恢复码数量
+func CountRecoveryCodes(db *gorm.DB, adminID int64) int64 {
+	var count int64
+	db.Model(&AdminRecoveryCode{}).Where("admin_id = ? AND used_at IS NULL", adminID).Count(&count)
+	return count
+}
+
+// DeleteRecoveryCodes 删除管理员的全部恢复码
+func DeleteRecoveryCodes(db *gorm.DB, adminID int64) error {
+	return db.Where("admin_id = ?", adminID).Delete(&AdminRecoveryCode{}).Error
+}
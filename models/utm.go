@@ -0,0 +1,49 @@
+package models
+
+import (
+	"database/sql/driver"
+	"net/url"
+)
+
+// UTMParams 跳转时自动追加到目标URL的UTM参数
+type UTMParams struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// IsEmpty 检查是否未设置任何UTM参数
+func (p UTMParams) IsEmpty() bool {
+	return p == UTMParams{}
+}
+
+// Values 转换为查询参数，未设置的参数不会出现在结果中
+func (p UTMParams) Values() url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	set("utm_source", p.Source)
+	set("utm_medium", p.Medium)
+	set("utm_campaign", p.Campaign)
+	set("utm_term", p.Term)
+	set("utm_content", p.Content)
+	return values
+}
+
+// Value 实现driver.Valuer接口
+func (p UTMParams) Value() (driver.Value, error) {
+	if p.IsEmpty() {
+		return "", nil
+	}
+	return valueJSON(p)
+}
+
+// Scan 实现sql.Scanner接口
+func (p *UTMParams) Scan(value interface{}) error {
+	return scanJSON(value, p)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxVariantsPerLink 每个短链接最多允许的分流目标数量
+const MaxVariantsPerLink = 10
+
+// ShortLinkVariant 短链接的A/B分流目标
+type ShortLinkVariant struct {
+	ID        int64     `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	LinkID    int64     `gorm:"index;type:bigint(20);not null" json:"linkId"`
+	Name      string    `gorm:"type:varchar(50);not null" json:"name"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	Weight    int       `gorm:"not null;default:1" json:"weight"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TableName 设置表名
+func (ShortLinkVariant) TableName() string {
+	return "short_link_variants"
+}
+
+// ShortLinkClick 短链接的点击事件
+type ShortLinkClick struct {
+	ID        int64     `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false"`
+	LinkID    int64     `gorm:"index;type:bigint(20);not null"`
+	VariantID int64     `gorm:"index;type:bigint(20);not null;default:0"`
+	Platform  string    `gorm:"type:varchar(16)"`
+	IP        string    `gorm:"type:varchar(64)"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName 设置表名
+func (ShortLinkClick) TableName() string {
+	return "short_link_clicks"
+}
+
+// VariantRequest 设置分流目标的请求项
+type VariantRequest struct {
+	Name   string `json:"name" binding:"required,max=50"`
+	URL    string `json:"url" binding:"required"`
+	Weight int    `json:"weight" binding:"required,min=1"`
+}
+
+// ValidateVariants 校验分流目标列表
+func ValidateVariants(variants []VariantRequest) error {
+	if len(variants) > MaxVariantsPerLink {
+		return errors.New("分流目标数量超过上限")
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range variants {
+		if seen[v.Name] {
+			return errors.New("分流目标名称重复: " + v.Name)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
+
+// PickVariant 按权重为访问者选择分流目标
+// 优先使用stickyID（通常来自Cookie）对应的目标，否则根据访问者标识的哈希值选择，
+// 保证同一访问者多次访问得到相同的结果
+func PickVariant(variants []ShortLinkVariant, stickyID int64, visitorKey string) *ShortLinkVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	if stickyID != 0 {
+		for i := range variants {
+			if variants[i].ID == stickyID {
+				return &variants[i]
+			}
+		}
+	}
+
+	totalWeight := 0
+	for _, v := range variants {
+		totalWeight += v.Weight
+	}
+	if totalWeight <= 0 {
+		return &variants[0]
+	}
+
+	hasher := fnv.New32a()
+	hasher.Write([]byte(visitorKey))
+	point := int(hasher.Sum32() % uint32(totalWeight))
+
+	for i := range variants {
+		point -= variants[i].Weight
+		if point < 0 {
+			return &variants[i]
+		}
+	}
+	return &variants[len(variants)-1]
+}
+
+// loadVariants 加载短链接的分流目标
+func loadVariants(db *gorm.DB, linkID int64) ([]ShortLinkVariant, error) {
+	var variants []ShortLinkVariant
+	if err := db.Where("link_id = ?", linkID).Order("id ASC").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
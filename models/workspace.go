@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWorkspaceID 默认工作区的ID，升级前已存在的管理员、短链接、域名和API密钥都属于默认工作区
+// 默认工作区的owner是平台所有者，负责创建和管理其他工作区
+const DefaultWorkspaceID int64 = 0
+
+// DefaultWorkspaceName 默认工作区的名称
+const DefaultWorkspaceName = "默认工作区"
+
+// Workspace 工作区，不同工作区的成员、短链接、域名和API密钥相互隔离
+// 每个管理员账户属于一个工作区，角色在所属工作区内生效
+type Workspace struct {
+	ID   int64  `gorm:"primaryKey;type:bigint(20);not null;auto_increment:false" json:"id"`
+	Name string `gorm:"uniqueIndex;type:varchar(100);not null" json:"name"`
+	// 工作区配额，为0时使用配置文件中的默认值
+	MaxActiveLinks  int64     `gorm:"not null;default:0" json:"maxActiveLinks"`
+	MaxDailyCreates int64     `gorm:"not null;default:0" json:"maxDailyCreates"`
+	MaxTTLSeconds   int64     `gorm:"not null;default:0" json:"maxTTLSeconds"`
+	CreatedBy       string    `gorm:"type:varchar(50)" json:"createdBy"`
+	CreatedAt       time.Time `gorm:"type:datetime;not null" json:"createdAt"`
+	UpdatedAt       time.Time `gorm:"type:datetime;not null" json:"updatedAt"`
+}
+
+// TableName 设置表名
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceRequest 创建或编辑工作区的请求
+type WorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// 工作区配额，为0时使用配置文件中的默认值
+	MaxActiveLinks  int64 `json:"maxActiveLinks" binding:"min=0"`
+	MaxDailyCreates int64 `json:"maxDailyCreates" binding:"min=0"`
+	MaxTTLSeconds   int64 `json:"maxTTLSeconds" binding:"min=0"`
+}
+
+// InWorkspace 将查询限定在指定工作区，适用于带有workspace_id字段的表
+func InWorkspace(workspaceID int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("workspace_id = ?", workspaceID)
+	}
+}
+
+// IsPlatformOwner 检查是否为默认工作区的owner
+func IsPlatformOwner(workspaceID int64, role string) bool {
+	return workspaceID == DefaultWorkspaceID && role == RoleOwner
+}
+
+// WorkspaceExists 检查工作区是否存在，默认工作区总是存在
+func WorkspaceExists(db *gorm.DB, workspaceID int64) bool {
+	if workspaceID == DefaultWorkspaceID {
+		return true
+	}
+	var count int64
+	db.Model(&Workspace{}).Where("id = ?", workspaceID).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,244 @@
+package policy
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"gorm.io/gorm"
+)
+
+// defaultReloadInterval 管理规则和恶意域名库的默认刷新间隔
+const defaultReloadInterval = time.Minute
+
+// 规则来源
+const (
+	sourceConfig = "config"
+	sourceAdmin  = "admin"
+)
+
+// Decision 策略检查结果
+type Decision struct {
+	Allowed bool
+	Reason  string // 被拒绝的原因
+}
+
+// rule 一条域名规则
+type rule struct {
+	pattern string
+	reason  string
+	source  string
+}
+
+// matches 检查主机是否匹配规则，*.example.com 匹配所有子域名，其他规则精确匹配
+func (r rule) matches(host string) bool {
+	if strings.HasPrefix(r.pattern, "*.") {
+		return strings.HasSuffix(host, r.pattern[1:])
+	}
+	return host == r.pattern
+}
+
+// Engine 目标域名策略引擎
+// 允许规则优先于拒绝规则和恶意域名库；配置为只允许模式时，未命中允许规则的域名都会被拒绝
+type Engine struct {
+	config *conf.PolicyConfig
+	db     *gorm.DB
+
+	allow    []rule
+	deny     []rule
+	feed     map[string]bool
+	feedMod  time.Time
+	loadedAt time.Time
+	mutex    sync.RWMutex
+	// reloading 是否正在定期刷新，同一时间只允许一个刷新
+	reloading atomic.Bool
+}
+
+// NewEngine 创建策略引擎并加载规则
+func NewEngine(config *conf.PolicyConfig, db *gorm.DB) (*Engine, error) {
+	e := &Engine{
+		config: config,
+		db:     db,
+		feed:   make(map[string]bool),
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NormalizePattern 规范化域名规则
+func NormalizePattern(pattern string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+}
+
+// Reload 重新加载配置规则、管理规则和恶意域名库
+// 恶意域名库无法读取时保留上次加载的内容，管理规则照常生效；查询管理规则失败时返回错误，保留当前规则
+func (e *Engine) Reload() error {
+	var allow, deny []rule
+	for _, pattern := range e.config.Allow {
+		allow = append(allow, rule{pattern: NormalizePattern(pattern), source: sourceConfig})
+	}
+	for _, pattern := range e.config.Deny {
+		deny = append(deny, rule{pattern: NormalizePattern(pattern), source: sourceConfig})
+	}
+
+	var policies []models.DomainPolicy
+	if err := e.db.Find(&policies).Error; err != nil {
+		// 失败时同样推迟下次定期刷新，避免数据库不可用时每次检查都查询数据库
+		e.mutex.Lock()
+		e.loadedAt = time.Now()
+		e.mutex.Unlock()
+		return fmt.Errorf("查询域名策略失败: %v", err)
+	}
+	for _, p := range policies {
+		r := rule{pattern: NormalizePattern(p.Pattern), reason: p.Reason, source: sourceAdmin}
+		if p.Action == models.PolicyActionAllow {
+			allow = append(allow, r)
+		} else {
+			deny = append(deny, r)
+		}
+	}
+
+	feed, feedMod, err := e.loadFeed()
+	if err != nil {
+		log.Printf("%v，继续使用上次加载的恶意域名库", err)
+	}
+
+	e.mutex.Lock()
+	e.allow = allow
+	e.deny = deny
+	if feed != nil {
+		e.feed = feed
+		e.feedMod = feedMod
+	}
+	e.loadedAt = time.Now()
+	e.mutex.Unlock()
+	return nil
+}
+
+// loadFeed 加载恶意域名库，文件未修改时返回nil
+func (e *Engine) loadFeed() (map[string]bool, time.Time, error) {
+	if e.config.FeedFile == "" {
+		return map[string]bool{}, time.Time{}, nil
+	}
+
+	info, err := os.Stat(e.config.FeedFile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("读取恶意域名库失败: %v", err)
+	}
+
+	e.mutex.RLock()
+	unchanged := info.ModTime().Equal(e.feedMod)
+	e.mutex.RUnlock()
+	if unchanged {
+		return nil, e.feedMod, nil
+	}
+
+	file, err := os.Open(e.config.FeedFile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("读取恶意域名库失败: %v", err)
+	}
+	defer file.Close()
+
+	feed := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// 兼容hosts格式（0.0.0.0 example.com）
+		if fields := strings.Fields(line); len(fields) > 1 {
+			line = fields[len(fields)-1]
+		}
+		feed[NormalizePattern(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("读取恶意域名库失败: %v", err)
+	}
+
+	log.Printf("已加载恶意域名库 %s，共 %d 条", e.config.FeedFile, len(feed))
+	return feed, info.ModTime(), nil
+}
+
+// refreshIfStale 定期刷新规则，使其他实例上的修改和恶意域名库的更新生效
+func (e *Engine) refreshIfStale() {
+	interval := defaultReloadInterval
+	if e.config.ReloadSeconds > 0 {
+		interval = time.Duration(e.config.ReloadSeconds) * time.Second
+	}
+
+	e.mutex.RLock()
+	stale := time.Since(e.loadedAt) > interval
+	e.mutex.RUnlock()
+
+	// 只由一个调用方刷新，其他调用方继续使用当前规则
+	if !stale || !e.reloading.CompareAndSwap(false, true) {
+		return
+	}
+	defer e.reloading.Store(false)
+	if err := e.Reload(); err != nil {
+		log.Printf("刷新域名策略失败: %v", err)
+	}
+}
+
+// CheckURL 检查目标URL是否允许使用，无法解析主机名的URL（如mailto:）不做域名检查
+func (e *Engine) CheckURL(rawURL string) Decision {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return Decision{Allowed: true}
+	}
+	return e.CheckHost(u.Hostname())
+}
+
+// CheckHost 检查主机是否允许使用
+func (e *Engine) CheckHost(host string) Decision {
+	e.refreshIfStale()
+	host = NormalizePattern(host)
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	for _, r := range e.allow {
+		if r.matches(host) {
+			return Decision{Allowed: true}
+		}
+	}
+
+	if e.config.AllowlistOnly {
+		return Decision{Reason: "域名不在允许列表中: " + host}
+	}
+
+	for _, r := range e.deny {
+		if r.matches(host) {
+			reason := "命中拒绝规则: " + r.pattern
+			if r.reason != "" {
+				reason += "（" + r.reason + "）"
+			}
+			return Decision{Reason: reason}
+		}
+	}
+
+	// 恶意域名库中的域名同时匹配其子域名
+	for name := host; name != ""; {
+		if e.feed[name] {
+			return Decision{Reason: "命中恶意域名库: " + name}
+		}
+		i := strings.Index(name, ".")
+		if i == -1 {
+			break
+		}
+		name = name[i+1:]
+	}
+
+	return Decision{Allowed: true}
+}
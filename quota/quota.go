@@ -0,0 +1,488 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// defaultKeyPrefix Redis中配额计数的默认键前缀
+const defaultKeyPrefix = "quota:"
+
+// dailyCounterTTL 每日创建计数的过期时间，跨天后旧计数自动清理
+const dailyCounterTTL = 48 * time.Hour
+
+// 配额主体类型
+const (
+	KindWorkspace = "workspace" // 工作区
+	KindUser      = "user"      // 使用登录令牌创建短链接的管理员
+	KindAPIKey    = "key"       // 使用API密钥创建短链接的调用方
+)
+
+// 配额限制项
+const (
+	LimitActiveLinks  = "activeLinks"
+	LimitDailyCreates = "dailyCreates"
+	LimitTTL          = "ttl"
+)
+
+// reserveScript 检查所有计数，全部未超限时才一起加1
+// ARGV前#KEYS个为对应计数的上限（0为不限制），后#KEYS个为对应计数的过期秒数（0为不过期）
+// 返回{0}表示成功，{1, 超限键的序号, 当前计数}表示超限
+var reserveScript = redis.NewScript(`
+local n = #KEYS
+for i = 1, n do
+  local max = tonumber(ARGV[i])
+  if max > 0 then
+    local used = tonumber(redis.call('GET', KEYS[i]) or '0')
+    if used >= max then
+      return {1, i, used}
+    end
+  end
+end
+for i = 1, n do
+  redis.call('INCR', KEYS[i])
+  local ttl = tonumber(ARGV[n + i])
+  if ttl > 0 then
+    redis.call('EXPIRE', KEYS[i], ttl)
+  end
+end
+return {0}
+`)
+
+// raiseScript 计数小于指定值时将其调高，用于按数据库校准当天创建数
+var raiseScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+local value = tonumber(ARGV[1])
+if used < value then
+  redis.call('SET', KEYS[1], value, 'EX', ARGV[2])
+  return value
+end
+return used
+`)
+
+// releaseScript 计数存在且大于0时减1
+var releaseScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if used > 0 then
+  return redis.call('DECR', KEYS[1])
+end
+return used
+`)
+
+// Subject 配额主体
+type Subject struct {
+	Kind string
+	ID   int64
+}
+
+// column 主体对应的短链接表字段
+func (s Subject) column() string {
+	switch s.Kind {
+	case KindWorkspace:
+		return "workspace_id"
+	case KindAPIKey:
+		return "api_key_id"
+	}
+	return "created_by"
+}
+
+// scope 将短链接查询限定为该主体创建的短链接
+// 使用登录令牌创建的短链接api_key_id为0，同一管理员创建的API密钥的短链接不计入管理员个人配额
+func (s Subject) scope(db *gorm.DB) *gorm.DB {
+	db = db.Where(s.column()+" = ?", s.ID)
+	if s.Kind == KindUser {
+		db = db.Where("api_key_id = 0")
+	}
+	return db
+}
+
+// Limits 配额限制，各项为0时不限制
+type Limits struct {
+	MaxActiveLinks  int64 `json:"maxActiveLinks"`
+	MaxDailyCreates int64 `json:"maxDailyCreates"`
+	MaxTTLSeconds   int64 `json:"maxTTLSeconds"`
+}
+
+// limitsFromConfig 根据配置创建配额限制
+func limitsFromConfig(config conf.QuotaLimits) Limits {
+	return Limits{
+		MaxActiveLinks:  config.MaxActiveLinks,
+		MaxDailyCreates: config.MaxDailyCreates,
+		MaxTTLSeconds:   config.MaxTTLSeconds,
+	}
+}
+
+// Check 一个主体的配额检查项
+type Check struct {
+	Subject Subject
+	Limits  Limits
+}
+
+// Usage 主体当前的配额使用情况
+type Usage struct {
+	Kind         string `json:"kind"`
+	ID           int64  `json:"id"`
+	Name         string `json:"name,omitempty"`
+	Limits       Limits `json:"limits"`
+	ActiveLinks  int64  `json:"activeLinks"`
+	DailyCreates int64  `json:"dailyCreates"`
+}
+
+// ExceededError 超出配额
+type ExceededError struct {
+	Subject Subject
+	Limit   string // 超出的限制项
+	Max     int64  // 上限
+	Used    int64  // 当前使用量，TTL超限时为请求的有效期
+}
+
+// Error 返回面向调用方的错误信息
+func (e *ExceededError) Error() string {
+	subject := map[string]string{
+		KindWorkspace: "工作区",
+		KindUser:      "当前账户",
+		KindAPIKey:    "当前API密钥",
+	}[e.Subject.Kind]
+	switch e.Limit {
+	case LimitActiveLinks:
+		return fmt.Sprintf("%s的有效短链接数已达上限（%d/%d），请删除不再使用的短链接或等待短链接过期", subject, e.Used, e.Max)
+	case LimitDailyCreates:
+		return fmt.Sprintf("%s今日创建的短链接数已达上限（%d/%d），请明天再试", subject, e.Used, e.Max)
+	}
+	return fmt.Sprintf("有效期超过%s的上限，最长为%d秒", subject, e.Max)
+}
+
+// Manager 短链接配额管理器
+// 有效短链接数和当天创建数保存在Redis中，计数不存在时从数据库初始化，并由定时任务定期按数据库校准；
+// 短链接过期后有效计数在下次校准时释放。Redis不可用时直接按数据库统计
+type Manager struct {
+	client *redis.Client
+	db     *gorm.DB
+	prefix string
+	user   Limits
+	// workspace 工作区的默认配额，工作区单独设置的非零值优先
+	workspace Limits
+
+	mutex      sync.Mutex
+	lastWarned time.Time
+}
+
+// NewManager 创建配额管理器，client为nil时只按数据库统计
+func NewManager(client *redis.Client, db *gorm.DB, config *conf.QuotaConfig) *Manager {
+	prefix := config.KeyPrefix
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	return &Manager{
+		client:    client,
+		db:        db,
+		prefix:    prefix,
+		user:      limitsFromConfig(config.User),
+		workspace: limitsFromConfig(config.Workspace),
+	}
+}
+
+// activeKey 有效短链接数的计数键
+func (m *Manager) activeKey(s Subject) string {
+	return fmt.Sprintf("%sactive:%s:%d", m.prefix, s.Kind, s.ID)
+}
+
+// dailyKey 指定日期创建数的计数键
+func (m *Manager) dailyKey(s Subject, day time.Time) string {
+	return fmt.Sprintf("%sdaily:%s:%d:%s", m.prefix, s.Kind, s.ID, day.Format("20060102"))
+}
+
+// startOfDay 返回当天零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// RetryAfter 距离当天创建数重置的时间
+func RetryAfter(now time.Time) time.Duration {
+	return startOfDay(now).AddDate(0, 0, 1).Sub(now)
+}
+
+// WorkspaceLimits 返回工作区的配额，工作区未单独设置的项使用配置中的默认值
+func (m *Manager) WorkspaceLimits(workspaceID int64) (Limits, error) {
+	limits := m.workspace
+	if workspaceID == models.DefaultWorkspaceID {
+		return limits, nil
+	}
+
+	var workspace models.Workspace
+	if err := m.db.Where("id = ?", workspaceID).First(&workspace).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return limits, nil
+		}
+		return limits, err
+	}
+	if workspace.MaxActiveLinks > 0 {
+		limits.MaxActiveLinks = workspace.MaxActiveLinks
+	}
+	if workspace.MaxDailyCreates > 0 {
+		limits.MaxDailyCreates = workspace.MaxDailyCreates
+	}
+	if workspace.MaxTTLSeconds > 0 {
+		limits.MaxTTLSeconds = workspace.MaxTTLSeconds
+	}
+	return limits, nil
+}
+
+// UserLimits 返回每个管理员或API密钥的配额
+func (m *Manager) UserLimits() Limits {
+	return m.user
+}
+
+// Checks 返回创建短链接时需要检查的配额：所属工作区，以及API密钥或管理员本人
+func (m *Manager) Checks(workspaceID, userID, apiKeyID int64) ([]Check, error) {
+	workspaceLimits, err := m.WorkspaceLimits(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []Check{{Subject: Subject{Kind: KindWorkspace, ID: workspaceID}, Limits: workspaceLimits}}
+	if apiKeyID != 0 {
+		checks = append(checks, Check{Subject: Subject{Kind: KindAPIKey, ID: apiKeyID}, Limits: m.user})
+	} else if userID != 0 {
+		checks = append(checks, Check{Subject: Subject{Kind: KindUser, ID: userID}, Limits: m.user})
+	}
+	return checks, nil
+}
+
+// LinkChecks 返回已有短链接所属主体的配额，即创建时检查的工作区和API密钥或管理员
+func (m *Manager) LinkChecks(link *models.DBShortLink) ([]Check, error) {
+	return m.Checks(link.WorkspaceID, link.CreatedBy, link.APIKeyID)
+}
+
+// CheckTTL 检查有效期是否超过配额中的上限，超限时返回*ExceededError
+func (m *Manager) CheckTTL(checks []Check, ttl time.Duration) error {
+	seconds := int64(ttl / time.Second)
+	for _, check := range checks {
+		if max := check.Limits.MaxTTLSeconds; max > 0 && seconds > max {
+			return &ExceededError{Subject: check.Subject, Limit: LimitTTL, Max: max, Used: seconds}
+		}
+	}
+	return nil
+}
+
+// Reserve 检查有效期和配额，未超限时占用一个有效短链接数和一个当天创建数
+// 超限时返回*ExceededError；短链接保存失败时需要调用Cancel归还
+func (m *Manager) Reserve(ctx context.Context, checks []Check, ttl time.Duration) error {
+	if err := m.CheckTTL(checks, ttl); err != nil {
+		return err
+	}
+	return m.reserve(ctx, checks, true)
+}
+
+// ReserveActive 已过期的短链接重新变为有效时占用一个有效短链接数，不计入当天创建数
+// 超限时返回*ExceededError；短链接保存失败时需要调用CancelActive归还
+func (m *Manager) ReserveActive(ctx context.Context, checks []Check) error {
+	return m.reserve(ctx, checks, false)
+}
+
+// reserve 占用有效短链接数，daily为true时同时占用当天创建数
+func (m *Manager) reserve(ctx context.Context, checks []Check, daily bool) error {
+	now := time.Now()
+	if m.client != nil {
+		err := m.reserveRedis(ctx, checks, now, daily)
+		var exceeded *ExceededError
+		if err == nil || errors.As(err, &exceeded) {
+			return err
+		}
+		if ctx.Err() == nil {
+			m.warn("Redis配额计数失败，改为按数据库统计: %v", err)
+		}
+	}
+	return m.reserveDB(checks, now, daily)
+}
+
+// reserveRedis 使用Redis计数原子地检查并占用配额
+func (m *Manager) reserveRedis(ctx context.Context, checks []Check, now time.Time, daily bool) error {
+	var keys []string
+	var maxes, ttls []interface{}
+	for _, check := range checks {
+		if err := m.ensureCounters(ctx, check.Subject, now); err != nil {
+			return err
+		}
+		keys = append(keys, m.activeKey(check.Subject))
+		maxes = append(maxes, check.Limits.MaxActiveLinks)
+		ttls = append(ttls, 0)
+		if daily {
+			keys = append(keys, m.dailyKey(check.Subject, now))
+			maxes = append(maxes, check.Limits.MaxDailyCreates)
+			ttls = append(ttls, int64(dailyCounterTTL/time.Second))
+		}
+	}
+
+	values, err := reserveScript.Run(ctx, m.client, keys, append(maxes, ttls...)...).Int64Slice()
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 || values[0] == 0 {
+		return nil
+	}
+	if len(values) != 3 {
+		return fmt.Errorf("配额脚本返回值无效: %v", values)
+	}
+
+	stride := int64(1)
+	if daily {
+		stride = 2
+	}
+	index := values[1] - 1
+	check := checks[index/stride]
+	exceeded := &ExceededError{Subject: check.Subject, Limit: LimitActiveLinks, Max: check.Limits.MaxActiveLinks, Used: values[2]}
+	if index%stride == 1 {
+		exceeded.Limit = LimitDailyCreates
+		exceeded.Max = check.Limits.MaxDailyCreates
+	}
+	return exceeded
+}
+
+// ensureCounters 计数不存在时按数据库初始化，已存在的计数不会被覆盖
+func (m *Manager) ensureCounters(ctx context.Context, subject Subject, now time.Time) error {
+	activeKey, dailyKey := m.activeKey(subject), m.dailyKey(subject, now)
+	exists, err := m.client.Exists(ctx, activeKey, dailyKey).Result()
+	if err != nil || exists == 2 {
+		return err
+	}
+
+	active, daily, err := m.countDB(subject, now)
+	if err != nil {
+		return err
+	}
+	pipe := m.client.Pipeline()
+	pipe.SetNX(ctx, activeKey, active, 0)
+	pipe.SetNX(ctx, dailyKey, daily, dailyCounterTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// countDB 从数据库统计主体的有效短链接数和当天创建数
+// 当天创建后又被删除的短链接已移入历史表，不计入当天创建数，由Redis计数保证不被重复使用
+func (m *Manager) countDB(subject Subject, now time.Time) (int64, int64, error) {
+	var active, daily int64
+	if err := m.db.Model(&models.DBShortLink{}).Scopes(subject.scope).
+		Where("expires_at > ?", now).Count(&active).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := m.db.Model(&models.DBShortLink{}).Scopes(subject.scope).
+		Where("created_at >= ?", startOfDay(now)).Count(&daily).Error; err != nil {
+		return 0, 0, err
+	}
+	return active, daily, nil
+}
+
+// reserveDB Redis不可用时按数据库统计检查配额，并发创建时可能略微超出上限
+func (m *Manager) reserveDB(checks []Check, now time.Time, daily bool) error {
+	for _, check := range checks {
+		if check.Limits.MaxActiveLinks <= 0 && (!daily || check.Limits.MaxDailyCreates <= 0) {
+			continue
+		}
+		active, created, err := m.countDB(check.Subject, now)
+		if err != nil {
+			return err
+		}
+		if max := check.Limits.MaxActiveLinks; max > 0 && active >= max {
+			return &ExceededError{Subject: check.Subject, Limit: LimitActiveLinks, Max: max, Used: active}
+		}
+		if max := check.Limits.MaxDailyCreates; daily && max > 0 && created >= max {
+			return &ExceededError{Subject: check.Subject, Limit: LimitDailyCreates, Max: max, Used: created}
+		}
+	}
+	return nil
+}
+
+// Cancel 短链接保存失败时归还Reserve占用的配额
+func (m *Manager) Cancel(ctx context.Context, checks []Check) {
+	m.cancel(ctx, checks, true)
+}
+
+// CancelActive 短链接保存失败时归还ReserveActive占用的配额
+func (m *Manager) CancelActive(ctx context.Context, checks []Check) {
+	m.cancel(ctx, checks, false)
+}
+
+// cancel 归还有效短链接数，daily为true时同时归还当天创建数
+func (m *Manager) cancel(ctx context.Context, checks []Check, daily bool) {
+	if m.client == nil {
+		return
+	}
+	// 与ReleaseActive相同，计数被校准或已过期时不会减为负数
+	// 管道中的EVALSHA无法在脚本未加载时回退，使用EVAL
+	now := time.Now()
+	pipe := m.client.Pipeline()
+	for _, check := range checks {
+		releaseScript.Eval(ctx, pipe, []string{m.activeKey(check.Subject)})
+		if daily {
+			releaseScript.Eval(ctx, pipe, []string{m.dailyKey(check.Subject, now)})
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.warn("归还配额失败: %v", err)
+	}
+}
+
+// ReleaseActive 删除短链接后释放有效短链接数，已过期的短链接不占用配额
+func (m *Manager) ReleaseActive(ctx context.Context, link *models.DBShortLink) {
+	if m.client == nil || !link.ExpiresAt.After(time.Now()) {
+		return
+	}
+	subjects := []Subject{{Kind: KindWorkspace, ID: link.WorkspaceID}}
+	if link.APIKeyID != 0 {
+		subjects = append(subjects, Subject{Kind: KindAPIKey, ID: link.APIKeyID})
+	} else if link.CreatedBy != 0 {
+		subjects = append(subjects, Subject{Kind: KindUser, ID: link.CreatedBy})
+	}
+
+	pipe := m.client.Pipeline()
+	for _, subject := range subjects {
+		// 计数不存在时不需要释放，下次使用时会从数据库初始化
+		releaseScript.Eval(ctx, pipe, []string{m.activeKey(subject)})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		m.warn("释放配额失败: %v", err)
+	}
+}
+
+// Usage 查询主体当前的配额使用情况，Redis中没有计数或Redis不可用时按数据库统计
+func (m *Manager) Usage(ctx context.Context, subject Subject, limits Limits) (Usage, error) {
+	usage := Usage{Kind: subject.Kind, ID: subject.ID, Limits: limits}
+	now := time.Now()
+	if m.client != nil {
+		values, err := m.client.MGet(ctx, m.activeKey(subject), m.dailyKey(subject, now)).Result()
+		if err == nil && values[0] != nil && values[1] != nil {
+			usage.ActiveLinks, _ = strconv.ParseInt(fmt.Sprint(values[0]), 10, 64)
+			usage.DailyCreates, _ = strconv.ParseInt(fmt.Sprint(values[1]), 10, 64)
+			return usage, nil
+		}
+		if err != nil {
+			m.warn("查询Redis配额计数失败，改为按数据库统计: %v", err)
+		}
+	}
+
+	var err error
+	usage.ActiveLinks, usage.DailyCreates, err = m.countDB(subject, now)
+	return usage, err
+}
+
+// warn 记录Redis配额计数失败，批量创建时每分钟最多记录一次
+func (m *Manager) warn(format string, args ...interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if time.Since(m.lastWarned) > time.Minute {
+		m.lastWarned = time.Now()
+		log.Printf(format, args...)
+	}
+}
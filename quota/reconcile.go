@@ -0,0 +1,108 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qiuxsgit/go-short-link/models"
+)
+
+// subjectCount 按主体分组的短链接数
+type subjectCount struct {
+	ID    int64
+	Count int64
+}
+
+// ReconcileResult 校准结果
+type ReconcileResult struct {
+	ActiveCounters int // 按数据库重置的有效短链接计数
+	DailyCounters  int // 按数据库调高的当天创建计数
+	ResetCounters  int // 数据库中已没有有效短链接而清零的计数
+}
+
+// Reconcile 按数据库校准Redis中的配额计数
+// 有效短链接数直接设为数据库中的数量，已过期的短链接在此时释放配额；
+// 当天创建数只会调高，当天创建后删除的短链接已移入历史表，数据库中的数量可能偏少。
+// 统计与写入之间新创建的短链接可能被少计，下次校准时修正
+func (m *Manager) Reconcile(ctx context.Context) (ReconcileResult, error) {
+	var result ReconcileResult
+	if m.client == nil {
+		return result, nil
+	}
+
+	now := time.Now()
+	active, err := m.groupCounts("expires_at > ?", now)
+	if err != nil {
+		return result, err
+	}
+	daily, err := m.groupCounts("created_at >= ?", startOfDay(now))
+	if err != nil {
+		return result, err
+	}
+
+	// 重置有效短链接数
+	found := make(map[string]bool, len(active))
+	pipe := m.client.Pipeline()
+	for subject, count := range active {
+		key := m.activeKey(subject)
+		found[key] = true
+		pipe.Set(ctx, key, count, 0)
+	}
+	iter := m.client.Scan(ctx, 0, m.prefix+"active:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if !found[iter.Val()] {
+			pipe.Set(ctx, iter.Val(), 0, 0)
+			result.ResetCounters++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return result, fmt.Errorf("扫描配额计数失败: %v", err)
+	}
+
+	// 调高当天创建数
+	ttl := int64(dailyCounterTTL / time.Second)
+	for subject, count := range daily {
+		raiseScript.Run(ctx, pipe, []string{m.dailyKey(subject, now)}, count, ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return result, fmt.Errorf("写入配额计数失败: %v", err)
+	}
+
+	result.ActiveCounters = len(active)
+	result.DailyCounters = len(daily)
+	return result, nil
+}
+
+// groupCounts 按工作区、API密钥和管理员分组统计满足条件的短链接数
+func (m *Manager) groupCounts(where string, args ...interface{}) (map[Subject]int64, error) {
+	groups := []struct {
+		kind   string
+		column string
+		filter string
+	}{
+		{KindWorkspace, "workspace_id", ""},
+		{KindAPIKey, "api_key_id", "api_key_id <> 0"},
+		{KindUser, "created_by", "api_key_id = 0 AND created_by <> 0"},
+	}
+
+	counts := make(map[Subject]int64)
+	for _, group := range groups {
+		query := m.db.Model(&models.DBShortLink{}).
+			Select(group.column+" AS id, COUNT(*) AS count").
+			Where(where, args...).
+			Group(group.column)
+		if group.filter != "" {
+			query = query.Where(group.filter)
+		}
+
+		var rows []subjectCount
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("统计短链接数失败: %v", err)
+		}
+		for _, row := range rows {
+			counts[Subject{Kind: group.kind, ID: row.ID}] = row.Count
+		}
+	}
+	return counts, nil
+}
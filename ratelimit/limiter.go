@@ -0,0 +1,227 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultKeyPrefix Redis中限流状态的默认键前缀
+const defaultKeyPrefix = "ratelimit:"
+
+// redisRetryInterval Redis失败后改用内存令牌桶的时长，避免每个请求都等待连接超时
+const redisRetryInterval = 5 * time.Second
+
+// maxMemoryBuckets 内存令牌桶数量超过该值时清理已回满的令牌桶
+const maxMemoryBuckets = 100000
+
+// tokenBucketScript 在Redis中原子地补充并消耗令牌，使用Redis服务器时间避免各实例时钟不一致
+var tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// Rule 令牌桶规则：每Period补充Requests个令牌，桶容量为Burst
+type Rule struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// RuleFromConfig 根据配置创建限流规则，Period未配置时为60秒，Burst未配置时等于Requests
+func RuleFromConfig(config conf.RateLimitRule) Rule {
+	rule := Rule{
+		Requests: config.Requests,
+		Period:   time.Duration(config.PeriodSeconds) * time.Second,
+		Burst:    config.Burst,
+	}
+	if rule.Period <= 0 {
+		rule.Period = time.Minute
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Requests
+	}
+	return rule
+}
+
+// Enabled 规则是否生效，Requests小于等于0时不限流
+func (r Rule) Enabled() bool {
+	return r.Requests > 0 && r.Period > 0
+}
+
+// ratePerMillisecond 每毫秒补充的令牌数
+func (r Rule) ratePerMillisecond() float64 {
+	return float64(r.Requests) / float64(r.Period.Milliseconds())
+}
+
+// Result 限流检查结果
+type Result struct {
+	Allowed    bool
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌数
+	Reset      time.Duration // 令牌桶回满所需时间
+	RetryAfter time.Duration // 被拒绝时需要等待的时间
+}
+
+// newResult 根据剩余令牌数计算限流结果
+func newResult(rule Rule, allowed bool, tokens float64) Result {
+	rate := rule.ratePerMillisecond()
+	result := Result{
+		Allowed:   allowed,
+		Limit:     rule.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(rule.Burst)-tokens)/rate) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1-tokens)/rate) * time.Millisecond
+	}
+	return result
+}
+
+// bucket 内存令牌桶，记录所属规则的补充速率和容量，清理时按各自的规则判断是否回满
+type bucket struct {
+	tokens float64
+	ts     time.Time
+	rate   float64
+	burst  float64
+}
+
+// Limiter 令牌桶限流器，状态保存在Redis中使多个实例共享限额，Redis不可用时使用内存令牌桶
+type Limiter struct {
+	client *redis.Client
+	prefix string
+
+	buckets      map[string]*bucket
+	mutex        sync.Mutex
+	redisRetryAt time.Time
+	lastWarned   time.Time
+}
+
+// NewLimiter 创建限流器，client为nil时只使用内存令牌桶
+func NewLimiter(client *redis.Client, prefix string) *Limiter {
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	return &Limiter{
+		client:  client,
+		prefix:  prefix,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow 从name规则下key对应的令牌桶中消耗一个令牌
+func (l *Limiter) Allow(ctx context.Context, name, key string, rule Rule) Result {
+	key = l.prefix + name + ":" + key
+	if l.client != nil && l.redisAvailable() {
+		result, err := l.allowRedis(ctx, key, rule)
+		if err == nil {
+			return result
+		}
+		// 请求被取消导致的失败不代表Redis不可用
+		if ctx.Err() == nil {
+			l.redisFailed(err)
+		}
+	}
+	return l.allowMemory(key, rule)
+}
+
+// allowRedis 使用Redis令牌桶
+func (l *Limiter) allowRedis(ctx context.Context, key string, rule Rule) (Result, error) {
+	rate := rule.ratePerMillisecond()
+	ttl := int64(math.Ceil(float64(rule.Burst)/rate)) + 1000
+	values, err := tokenBucketScript.Run(ctx, l.client, []string{key}, rate, rule.Burst, ttl).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("限流脚本返回值无效: %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	text, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("限流脚本返回值无效: %v", values)
+	}
+	return newResult(rule, allowed == 1, tokens), nil
+}
+
+// allowMemory 使用内存令牌桶，限额只在当前实例内有效
+func (l *Limiter) allowMemory(key string, rule Rule) Result {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxMemoryBuckets {
+			l.cleanup(now)
+		}
+		b = &bucket{tokens: float64(rule.Burst), ts: now, rate: rule.ratePerMillisecond(), burst: float64(rule.Burst)}
+		l.buckets[key] = b
+	}
+
+	elapsed := float64(now.Sub(b.ts).Milliseconds())
+	b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	b.ts = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return newResult(rule, allowed, b.tokens)
+}
+
+// cleanup 删除已经回满的令牌桶，回满的桶与新建的桶等价
+func (l *Limiter) cleanup(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+float64(now.Sub(b.ts).Milliseconds())*b.rate >= b.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// redisAvailable 检查是否可以尝试使用Redis
+func (l *Limiter) redisAvailable() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return time.Now().After(l.redisRetryAt)
+}
+
+// redisFailed 记录Redis限流失败，在一段时间内改用内存令牌桶，日志每分钟最多记录一次
+func (l *Limiter) redisFailed(err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.redisRetryAt = time.Now().Add(redisRetryInterval)
+	if time.Since(l.lastWarned) > time.Minute {
+		l.lastWarned = time.Now()
+		log.Printf("Redis限流失败，改用内存限流: %v", err)
+	}
+}
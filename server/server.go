@@ -17,21 +17,34 @@ import (
 	"github.com/qiuxsgit/go-short-link/conf"
 	"github.com/qiuxsgit/go-short-link/handlers"
 	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+	"github.com/qiuxsgit/go-short-link/quota"
+	"github.com/qiuxsgit/go-short-link/ratelimit"
+	"github.com/qiuxsgit/go-short-link/templates"
+	"github.com/qiuxsgit/go-short-link/utils"
 )
 
 // Server 表示短链接服务器
 type Server struct {
 	config       *conf.Config
 	store        models.Store
+	policy       *policy.Engine
+	limiter      *ratelimit.Limiter
+	quota        *quota.Manager
+	revocation   *utils.TokenRevocation
 	adminServer  *http.Server
 	accessServer *http.Server
 }
 
 // NewServer 创建一个新的服务器实例
-func NewServer(config *conf.Config, store models.Store) *Server {
+func NewServer(config *conf.Config, store models.Store, engine *policy.Engine, limiter *ratelimit.Limiter, quotas *quota.Manager, revocation *utils.TokenRevocation) *Server {
 	return &Server{
-		config: config,
-		store:  store,
+		config:     config,
+		store:      store,
+		policy:     engine,
+		limiter:    limiter,
+		quota:      quotas,
+		revocation: revocation,
 	}
 }
 
@@ -50,14 +63,20 @@ func (s *Server) Initialize() {
 		log.Fatal("存储必须是GormStore类型")
 	}
 
+	// 加载页面模板
+	pages, err := templates.NewRenderer(s.config.Server.Access.TemplateDir, s.config.Server.Access.DefaultLanguage)
+	if err != nil {
+		log.Fatalf("加载页面模板失败: %v", err)
+	}
+
 	// 创建管理API处理器
-	adminHandler := handlers.NewShortLinkHandler(s.store, s.config.Server.Access.BaseURL)
+	adminHandler := handlers.NewShortLinkHandler(s.store, &s.config.Server.Access, pages, s.policy, s.quota)
 
 	// 创建访问API处理器
-	accessHandler := handlers.NewShortLinkHandler(s.store, s.config.Server.Access.BaseURL)
+	accessHandler := handlers.NewShortLinkHandler(s.store, &s.config.Server.Access, pages, s.policy, s.quota)
 
 	// 创建管理员处理器
-	adminUserHandler := handlers.NewAdminHandler(gormStore, s.config)
+	adminUserHandler := handlers.NewAdminHandler(gormStore, s.config, s.policy, s.quota, s.revocation)
 
 	// 创建管理API路由
 	adminRouter := gin.Default()
@@ -93,11 +112,11 @@ func (s *Server) Initialize() {
 		}
 	}())
 
-	api.SetupAdminRoutes(adminRouter, adminHandler, adminUserHandler, &s.config.Server.Admin)
+	api.SetupAdminRoutes(adminRouter, adminHandler, adminUserHandler, gormStore, s.limiter, s.revocation, s.config)
 
 	// 创建访问API路由
 	accessRouter := gin.Default()
-	api.SetupAccessRoutes(accessRouter, accessHandler)
+	api.SetupAccessRoutes(accessRouter, accessHandler, s.limiter, s.config)
 
 	// 创建管理API服务器
 	s.adminServer = &http.Server{
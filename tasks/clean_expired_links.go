@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
 	"gorm.io/gorm"
 )
 
@@ -144,5 +145,6 @@ func (t *CleanExpiredLinksTask) ensureHistoryTableExists(historyTableName string
 		log.Printf("已创建历史表: %s", historyTableName)
 	}
 
-	return nil
+	// 补齐短链接表后续新增的字段
+	return models.EnsureHistoryTable(t.db, historyTableName)
 }
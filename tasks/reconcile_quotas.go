@@ -0,0 +1,53 @@
+package tasks
+
+import (
+	"context"
+	"log"
+
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/quota"
+)
+
+// ReconcileQuotasTask 按数据库校准Redis中配额计数的任务
+// 过期的短链接不会实时释放配额，由该任务在校准时释放
+type ReconcileQuotasTask struct {
+	config *conf.ReconcileQuotasConfig
+	quota  *quota.Manager
+}
+
+// NewReconcileQuotasTask 创建一个新的配额校准任务
+func NewReconcileQuotasTask(config *conf.ReconcileQuotasConfig, manager *quota.Manager) *ReconcileQuotasTask {
+	return &ReconcileQuotasTask{
+		config: config,
+		quota:  manager,
+	}
+}
+
+// Name 返回任务名称
+func (t *ReconcileQuotasTask) Name() string {
+	return "ReconcileQuotas"
+}
+
+// IsEnabled 检查任务是否启用
+func (t *ReconcileQuotasTask) IsEnabled() bool {
+	return t.config.Enabled
+}
+
+// Schedule 返回任务的调度表达式
+func (t *ReconcileQuotasTask) Schedule() string {
+	return t.config.Cron
+}
+
+// Run 执行任务
+func (t *ReconcileQuotasTask) Run() error {
+	log.Println("开始校准配额计数...")
+
+	result, err := t.quota.Reconcile(context.Background())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("校准完成，重置 %d 个有效短链接计数，清零 %d 个，调高 %d 个当天创建计数",
+		result.ActiveCounters, result.ResetCounters, result.DailyCounters)
+	return nil
+}
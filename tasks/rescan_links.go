@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/qiuxsgit/go-short-link/conf"
+	"github.com/qiuxsgit/go-short-link/models"
+	"github.com/qiuxsgit/go-short-link/policy"
+)
+
+// RescanLinksTask 按域名策略重新扫描有效短链接的任务
+// 目标地址在创建后被加入拒绝规则或恶意域名库的短链接会被停用，并记录停用原因
+type RescanLinksTask struct {
+	config *conf.RescanLinksConfig
+	store  *models.GormStore
+	policy *policy.Engine
+}
+
+// NewRescanLinksTask 创建一个新的短链接重新扫描任务
+func NewRescanLinksTask(config *conf.RescanLinksConfig, store *models.GormStore, engine *policy.Engine) *RescanLinksTask {
+	return &RescanLinksTask{
+		config: config,
+		store:  store,
+		policy: engine,
+	}
+}
+
+// Name 返回任务名称
+func (t *RescanLinksTask) Name() string {
+	return "RescanLinks"
+}
+
+// IsEnabled 检查任务是否启用
+func (t *RescanLinksTask) IsEnabled() bool {
+	return t.config.Enabled
+}
+
+// Schedule 返回任务的调度表达式
+func (t *RescanLinksTask) Schedule() string {
+	return t.config.Cron
+}
+
+// Run 执行任务
+func (t *RescanLinksTask) Run() error {
+	log.Println("开始按域名策略扫描短链接...")
+
+	batchSize := t.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500 // 默认批处理大小
+	}
+
+	db := t.store.GetDB()
+	now := time.Now()
+	var lastID int64
+	var scannedCount, disabledCount int
+
+	for {
+		// 按ID分批查询有效的短链接
+		var links []models.DBShortLink
+		if err := db.Where("id > ? AND status = ? AND expires_at > ?", lastID, models.LinkStatusActive, now).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&links).Error; err != nil {
+			return fmt.Errorf("查询短链接失败: %v", err)
+		}
+		if len(links) == 0 {
+			break
+		}
+
+		// 查询这一批短链接的分流目标
+		ids := make([]int64, len(links))
+		for i, link := range links {
+			ids[i] = link.ID
+		}
+		var variants []models.ShortLinkVariant
+		if err := db.Where("link_id IN ?", ids).Find(&variants).Error; err != nil {
+			return fmt.Errorf("查询分流目标失败: %v", err)
+		}
+		variantURLs := make(map[int64][]string)
+		for _, v := range variants {
+			variantURLs[v.LinkID] = append(variantURLs[v.LinkID], v.URL)
+		}
+
+		for _, link := range links {
+			scannedCount++
+			reason := t.check(&link, variantURLs[link.ID])
+			if reason == "" {
+				continue
+			}
+
+			// 停用命中策略的短链接，并从缓存中删除
+			if err := db.Model(&models.DBShortLink{}).
+				Where("id = ? AND status = ?", link.ID, models.LinkStatusActive).
+				Updates(map[string]interface{}{
+					"status":            models.LinkStatusDisabled,
+					"status_reason":     reason,
+					"status_updated_at": time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("停用短链接失败: %v", err)
+			}
+			t.store.RemoveFromCache(link.DomainID, link.ShortCode)
+
+			log.Printf("短链接 %s（ID: %d）已停用: %s", link.ShortCode, link.ID, reason)
+			disabledCount++
+		}
+
+		lastID = links[len(links)-1].ID
+	}
+
+	log.Printf("扫描完成，共扫描 %d 个短链接，停用 %d 个", scannedCount, disabledCount)
+	return nil
+}
+
+// check 检查短链接的所有目标地址，返回停用原因，全部允许时返回空字符串
+func (t *RescanLinksTask) check(link *models.DBShortLink, variantURLs []string) string {
+	targets := []string{link.OriginalURL}
+	for i := range link.DeviceRules {
+		for _, target := range link.DeviceRules[i].PolicyTargets() {
+			targets = append(targets, target.URL)
+		}
+	}
+	targets = append(targets, variantURLs...)
+
+	for _, target := range targets {
+		if decision := t.policy.CheckURL(target); !decision.Allowed {
+			return decision.Reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package templates
+
+// DefaultLanguage 默认页面语言
+const DefaultLanguage = "zh"
+
+// catalogs 页面文案，按语言区分
+var catalogs = map[string]map[string]string{
+	"zh": {
+		"notFoundTitle":       "页面不存在",
+		"notFoundMessage":     "抱歉，您访问的短链接不存在或已过期。",
+		"expiredTitle":        "链接已过期",
+		"expiredMessage":      "抱歉，您访问的短链接已过期。",
+		"inactiveTitle":       "链接尚未生效",
+		"inactiveMessage":     "该短链接尚未生效，请稍后再试。",
+		"disabledTitle":       "链接已停用",
+		"disabledMessage":     "该短链接已被停用，无法继续访问。",
+		"quarantinedMessage":  "该短链接因安全原因暂停访问，正在审核中。",
+		"passwordTitle":       "需要密码",
+		"passwordMessage":     "该短链接受密码保护，请输入密码后继续访问。",
+		"passwordPlaceholder": "请输入密码",
+		"passwordSubmit":      "继续访问",
+		"passwordError":       "密码错误，请重试。",
+		"previewTitle":        "即将离开本站",
+		"previewMessage":      "该短链接将跳转到以下地址，请确认目标可信后继续访问。",
+		"previewDomain":       "目标域名",
+		"previewURL":          "目标地址",
+		"previewCreatedAt":    "创建时间",
+		"previewExpiresAt":    "过期时间",
+		"previewContinue":     "继续访问",
+		"rateLimitedTitle":    "访问过于频繁",
+		"rateLimitedMessage":  "您的访问过于频繁，请稍后再试。",
+		"deepLinkTitle":       "正在打开...",
+		"deepLinkMessage":     "正在打开应用，如未自动跳转请",
+		"redirectTitle":       "正在跳转...",
+		"redirectMessage":     "正在跳转，如未自动跳转请",
+		"clickHere":           "点击这里",
+	},
+	"en": {
+		"notFoundTitle":       "Page Not Found",
+		"notFoundMessage":     "Sorry, the short link you requested does not exist or has expired.",
+		"expiredTitle":        "Link Expired",
+		"expiredMessage":      "Sorry, the short link you requested has expired.",
+		"inactiveTitle":       "Link Not Yet Active",
+		"inactiveMessage":     "This short link is not active yet. Please try again later.",
+		"disabledTitle":       "Link Disabled",
+		"disabledMessage":     "This short link has been disabled.",
+		"quarantinedMessage":  "This short link has been suspended for security reasons and is under review.",
+		"passwordTitle":       "Password Required",
+		"passwordMessage":     "This short link is password protected. Enter the password to continue.",
+		"passwordPlaceholder": "Password",
+		"passwordSubmit":      "Continue",
+		"passwordError":       "Incorrect password, please try again.",
+		"previewTitle":        "You Are Leaving This Site",
+		"previewMessage":      "This short link points to the address below. Continue only if you trust it.",
+		"previewDomain":       "Domain",
+		"previewURL":          "Destination",
+		"previewCreatedAt":    "Created",
+		"previewExpiresAt":    "Expires",
+		"previewContinue":     "Continue",
+		"rateLimitedTitle":    "Too Many Requests",
+		"rateLimitedMessage":  "You are sending requests too quickly. Please try again later.",
+		"deepLinkTitle":       "Opening...",
+		"deepLinkMessage":     "Opening the app. If nothing happens,",
+		"redirectTitle":       "Redirecting...",
+		"redirectMessage":     "Redirecting. If nothing happens,",
+		"clickHere":           "click here",
+	},
+}
@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed *.html
+var files embed.FS
+
+// 页面模板名称
+const (
+	PageNotFound    = "404.html"
+	PageExpired     = "expired.html"
+	PageInactive    = "inactive.html"
+	PageDisabled    = "disabled.html"
+	PagePassword    = "password.html"
+	PagePreview     = "preview.html"
+	PageRateLimited = "ratelimited.html"
+	PageDeepLink    = "deeplink.html"
+	PageRedirect    = "redirect.html"
+	PageOpenGraph   = "opengraph.html"
+)
+
+// Renderer 页面渲染器
+// 模板按以下顺序叠加，后者覆盖前者的同名模板：
+//  1. 内置的默认模板
+//  2. 模板目录下的 *.html
+//  3. 模板目录下以域名命名的子目录中的 *.html，仅对该域名的请求生效
+type Renderer struct {
+	global  *template.Template
+	domains map[string]*template.Template
+	matcher language.Matcher
+	langs   []string
+}
+
+// NewRenderer 创建页面渲染器，dir为空时只使用内置模板
+// defaultLang 为无法从Accept-Language匹配时使用的语言
+func NewRenderer(dir, defaultLang string) (*Renderer, error) {
+	base, err := template.ParseFS(files, "*.html")
+	if err != nil {
+		return nil, fmt.Errorf("解析内置模板失败: %v", err)
+	}
+
+	r := &Renderer{
+		global:  base,
+		domains: make(map[string]*template.Template),
+	}
+	r.initLanguages(defaultLang)
+
+	if dir == "" {
+		return r, nil
+	}
+
+	// 加载全局覆盖模板
+	if r.global, err = overlay(base, dir); err != nil {
+		return nil, err
+	}
+
+	// 加载按域名覆盖的模板
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板目录失败: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		domain := strings.ToLower(entry.Name())
+		tmpl, err := overlay(r.global, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		r.domains[domain] = tmpl
+		log.Printf("已加载域名 %s 的页面模板", domain)
+	}
+
+	return r, nil
+}
+
+// overlay 复制模板集合并使用目录下的模板覆盖同名模板
+func overlay(base *template.Template, dir string) (*template.Template, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return base, nil
+	}
+
+	tmpl, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl, err = tmpl.ParseFiles(matches...); err != nil {
+		return nil, fmt.Errorf("解析模板目录 %s 失败: %v", dir, err)
+	}
+	return tmpl, nil
+}
+
+// initLanguages 初始化语言匹配器，默认语言排在首位
+func (r *Renderer) initLanguages(defaultLang string) {
+	if _, ok := catalogs[defaultLang]; !ok {
+		defaultLang = DefaultLanguage
+	}
+
+	r.langs = []string{defaultLang}
+	for lang := range catalogs {
+		if lang != defaultLang {
+			r.langs = append(r.langs, lang)
+		}
+	}
+
+	tags := make([]language.Tag, len(r.langs))
+	for i, lang := range r.langs {
+		tags[i] = language.MustParse(lang)
+	}
+	r.matcher = language.NewMatcher(tags)
+}
+
+// MatchLanguage 根据Accept-Language请求头选择页面语言
+func (r *Renderer) MatchLanguage(acceptLanguage string) string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := r.matcher.Match(tags...)
+	return r.langs[index]
+}
+
+// Render 渲染页面，host用于选择域名覆盖模板
+// data中会额外注入 T（当前语言的文案）和 Lang（当前语言）
+func (r *Renderer) Render(w io.Writer, host, lang, page string, data map[string]interface{}) error {
+	tmpl := r.global
+	if domainTmpl, ok := r.domains[strings.ToLower(stripPort(host))]; ok {
+		tmpl = domainTmpl
+	}
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	data["T"] = catalogs[lang]
+	data["Lang"] = lang
+
+	return tmpl.ExecuteTemplate(w, page, data)
+}
+
+// stripPort 去掉Host中的端口
+func stripPort(host string) string {
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.HasSuffix(host, "]") {
+		return host[:i]
+	}
+	return host
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,8 +10,8 @@ import (
 )
 
 var (
-	// 密钥，实际应用中应该从配置文件或环境变量中获取
-	jwtSecret = []byte("go-short-link-secret-key")
+	// 签名密钥集合，启动时由配置通过SetKeySet替换
+	jwtKeys = mustDefaultKeySet()
 
 	// ErrInvalidToken 无效的令牌
 	ErrInvalidToken = errors.New("无效的令牌")
@@ -17,44 +19,107 @@ var (
 	ErrExpiredToken = errors.New("令牌已过期")
 )
 
+// TokenPurposePreAuth 两步验证中间令牌的用途，只能用于提交验证码
+const TokenPurposePreAuth = "2fa"
+
 // JWTClaims 自定义JWT声明
 type JWTClaims struct {
 	UserID   int64  `json:"userId"`
 	Username string `json:"username"`
+	// Role 管理员角色，签发时从账户读取，角色变更后通过吊销旧令牌立即生效
+	Role string `json:"role,omitempty"`
+	// WorkspaceID 所属工作区，管理接口的查询都限定在该工作区内
+	WorkspaceID int64 `json:"wid,omitempty"`
+	// Purpose 令牌用途，访问令牌为空
+	Purpose string `json:"purpose,omitempty"`
+	// SessionID 登录会话ID，同一会话中刷新得到的访问令牌相同，用于退出登录时吊销整个会话
+	SessionID string `json:"sid,omitempty"`
+	// IssuedAtMs 毫秒精度的签发时间，iat只精确到秒，用户吊销时间按毫秒比较
+	IssuedAtMs int64 `json:"iatms,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(userID int64, username string, expireDuration time.Duration) (string, error) {
+// GenerateToken 生成属于指定登录会话的访问令牌，每个令牌带有唯一的jti
+func GenerateToken(userID int64, username, role string, workspaceID int64, sessionID string, expireDuration time.Duration) (string, error) {
+	return generateToken(userID, username, role, workspaceID, "", sessionID, expireDuration)
+}
+
+// GeneratePreAuthToken 生成密码验证通过、等待两步验证的中间令牌
+func GeneratePreAuthToken(userID int64, username string, expireDuration time.Duration) (string, error) {
+	return generateToken(userID, username, "", 0, TokenPurposePreAuth, "", expireDuration)
+}
+
+// GenerateTokenID 生成随机的令牌ID
+func GenerateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// generateToken 生成指定用途的JWT令牌
+func generateToken(userID int64, username, role string, workspaceID int64, purpose, sessionID string, expireDuration time.Duration) (string, error) {
 	// 设置过期时间
-	expireTime := time.Now().Add(expireDuration)
+	now := time.Now()
+	expireTime := now.Add(expireDuration)
+
+	jti, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
 
 	// 创建声明
 	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		WorkspaceID: workspaceID,
+		Purpose:     purpose,
+		SessionID:   sessionID,
+		IssuedAtMs:  now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expireTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "go-short-link",
 			Subject:   username,
 		},
 	}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// 签名令牌
-	return token.SignedString(jwtSecret)
+	// 使用当前密钥签名令牌
+	return jwtKeys.sign(claims)
 }
 
-// ParseToken 解析JWT令牌
+// ParseToken 解析访问令牌，其他用途的令牌视为无效
 func ParseToken(tokenString string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ParsePreAuthToken 解析两步验证中间令牌
+func ParsePreAuthToken(tokenString string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != TokenPurposePreAuth {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parseToken 解析并校验JWT令牌
+func parseToken(tokenString string) (*JWTClaims, error) {
 	// 解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, jwtKeys.verifyKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -77,9 +142,17 @@ func ParseToken(tokenString string) (*JWTClaims, error) {
 	return claims, nil
 }
 
-// SetJWTSecret 设置JWT密钥
-func SetJWTSecret(secret string) {
-	if secret != "" {
-		jwtSecret = []byte(secret)
+// SetKeySet 设置JWT签名密钥集合
+func SetKeySet(keys *KeySet) {
+	jwtKeys = keys
+}
+
+// mustDefaultKeySet 使用默认密钥创建密钥集合
+func mustDefaultKeySet() *KeySet {
+	key, _ := NewHMACKey(LegacyKeyID, DefaultJWTSecret)
+	keys, err := NewKeySet(LegacyKeyID, []*SigningKey{key})
+	if err != nil {
+		panic(err)
 	}
+	return keys
 }
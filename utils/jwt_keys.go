@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWT签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// DefaultJWTSecret 示例配置中的默认密钥，release模式下禁止使用
+const DefaultJWTSecret = "go-short-link-secret-key"
+
+// LegacyKeyID 配置单个jwt.secret时使用的密钥ID，也用于校验没有kid头的旧令牌
+const LegacyKeyID = "default"
+
+// SigningKey JWT签名密钥
+// 只配置了公钥的非对称密钥只能用于校验，不能设为当前签名密钥
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// RetireAt 退役时间，此后不再接受该密钥签发的令牌，零值表示不退役
+	RetireAt time.Time
+}
+
+// CanSign 是否可以用于签发令牌
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// IsRetired 在指定时间是否已退役
+func (k *SigningKey) IsRetired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// IsDefaultSecret 是否为使用默认密钥的HS256密钥
+func (k *SigningKey) IsDefaultSecret() bool {
+	secret, ok := k.signKey.([]byte)
+	return ok && string(secret) == DefaultJWTSecret
+}
+
+// NewHMACKey 创建HS256密钥
+func NewHMACKey(kid, secret string) (*SigningKey, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("JWT密钥%s未配置secret", kid)
+	}
+	return &SigningKey{
+		ID:        kid,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}, nil
+}
+
+// LoadSigningKey 按算法加载签名密钥
+// HS256使用secret；RS256和EdDSA从PEM文件读取私钥和公钥，只提供私钥时从私钥推导公钥，只提供公钥时只能用于校验
+func LoadSigningKey(kid, algorithm, secret, privateKeyFile, publicKeyFile string) (*SigningKey, error) {
+	if kid == "" {
+		return nil, errors.New("JWT密钥缺少kid")
+	}
+
+	switch strings.ToUpper(algorithm) {
+	case "", AlgorithmHS256:
+		return NewHMACKey(kid, secret)
+	case strings.ToUpper(AlgorithmRS256):
+		return loadAsymmetricKey(kid, jwt.SigningMethodRS256, privateKeyFile, publicKeyFile,
+			func(data []byte) (interface{}, interface{}, error) {
+				key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+				if err != nil {
+					return nil, nil, err
+				}
+				return key, &key.PublicKey, nil
+			},
+			func(data []byte) (interface{}, error) {
+				return jwt.ParseRSAPublicKeyFromPEM(data)
+			})
+	case strings.ToUpper(AlgorithmEdDSA):
+		return loadAsymmetricKey(kid, jwt.SigningMethodEdDSA, privateKeyFile, publicKeyFile,
+			func(data []byte) (interface{}, interface{}, error) {
+				key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+				if err != nil {
+					return nil, nil, err
+				}
+				signer, ok := key.(ed25519.PrivateKey)
+				if !ok {
+					return nil, nil, errors.New("不是Ed25519私钥")
+				}
+				return signer, signer.Public(), nil
+			},
+			func(data []byte) (interface{}, error) {
+				return jwt.ParseEdPublicKeyFromPEM(data)
+			})
+	}
+	return nil, fmt.Errorf("JWT密钥%s使用了不支持的算法: %s", kid, algorithm)
+}
+
+// loadAsymmetricKey 从PEM文件加载非对称密钥
+func loadAsymmetricKey(kid string, method jwt.SigningMethod, privateKeyFile, publicKeyFile string,
+	parsePrivate func([]byte) (interface{}, interface{}, error), parsePublic func([]byte) (interface{}, error)) (*SigningKey, error) {
+	if privateKeyFile == "" && publicKeyFile == "" {
+		return nil, fmt.Errorf("JWT密钥%s未配置私钥或公钥文件", kid)
+	}
+
+	key := &SigningKey{ID: kid, Method: method}
+	if privateKeyFile != "" {
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取JWT密钥%s的私钥文件失败: %v", kid, err)
+		}
+		signKey, verifyKey, err := parsePrivate(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析JWT密钥%s的私钥失败: %v", kid, err)
+		}
+		key.signKey = signKey
+		key.verifyKey = verifyKey
+	}
+	if publicKeyFile != "" {
+		data, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取JWT密钥%s的公钥文件失败: %v", kid, err)
+		}
+		verifyKey, err := parsePublic(data)
+		if err != nil {
+			return nil, fmt.Errorf("解析JWT密钥%s的公钥失败: %v", kid, err)
+		}
+		if key.verifyKey != nil && !publicKeyMatches(key.verifyKey, verifyKey) {
+			return nil, fmt.Errorf("JWT密钥%s的公钥与私钥不匹配", kid)
+		}
+		key.verifyKey = verifyKey
+	}
+	return key, nil
+}
+
+// publicKeyMatches 比较两个公钥是否相同
+func publicKeyMatches(a, b interface{}) bool {
+	switch key := a.(type) {
+	case *rsa.PublicKey:
+		return key.Equal(b)
+	case ed25519.PublicKey:
+		return key.Equal(b)
+	}
+	return false
+}
+
+// KeySet JWT密钥集合
+// 新令牌使用当前密钥签发并在kid头中记录密钥ID，校验时按kid选择密钥，
+// 轮换后旧密钥继续用于校验，直到退役时间或从配置中移除
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// NewKeySet 创建密钥集合，activeID为签发新令牌使用的密钥
+func NewKeySet(activeID string, keys []*SigningKey) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("JWT密钥ID重复: %s", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+
+	active, ok := set.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("当前JWT密钥%s不存在", activeID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("当前JWT密钥%s缺少私钥，不能签发令牌", activeID)
+	}
+	if active.IsRetired(time.Now()) {
+		return nil, fmt.Errorf("当前JWT密钥%s已退役", activeID)
+	}
+	set.active = active
+	return set, nil
+}
+
+// Active 返回当前签名密钥
+func (s *KeySet) Active() *SigningKey {
+	return s.active
+}
+
+// Keys 返回所有密钥
+func (s *KeySet) Keys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// sign 使用当前密钥签名令牌
+func (s *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.signKey)
+}
+
+// verifyKey 按令牌的kid和算法选择校验密钥，作为jwt.Keyfunc使用
+// 没有kid头的令牌由轮换前的单密钥配置签发，使用LegacyKeyID对应的密钥校验
+func (s *KeySet) verifyKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+	key, ok := s.keys[kid]
+	if !ok || key.verifyKey == nil || key.IsRetired(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+	// 算法必须与密钥一致，防止算法混淆攻击
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// 二维码输出格式
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// QROptions 二维码生成选项
+type QROptions struct {
+	Format     string      // 输出格式: png或svg
+	Size       int         // 图片边长（像素）
+	Level      string      // 纠错等级: L、M、Q、H
+	Margin     int         // 四周留白（模块数）
+	Foreground color.RGBA  // 前景色
+	Background color.RGBA  // 背景色
+	Logo       image.Image // 可选的中心Logo
+}
+
+// ParseRecoveryLevel 解析纠错等级
+func ParseRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "", "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("无效的纠错等级: %s", level)
+}
+
+// ParseHexColor 解析十六进制颜色，支持 #RGB、#RRGGBB 格式，#可省略
+func ParseHexColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	var r, g, b uint8
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("无效的颜色: %s", s)
+	}
+	if _, err := fmt.Sscanf(s, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("无效的颜色: %s", s)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}, nil
+}
+
+// RenderQRCode 按选项生成二维码，返回图片内容及对应的Content-Type
+func RenderQRCode(content string, opts QROptions) ([]byte, string, error) {
+	level, err := ParseRecoveryLevel(opts.Level)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 中心Logo会遮挡部分模块，需要使用最高纠错等级
+	if opts.Logo != nil {
+		level = qrcode.Highest
+	}
+
+	qr, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, "", err
+	}
+	qr.DisableBorder = true
+
+	// 在二维码矩阵四周添加留白
+	modules := qr.Bitmap()
+	if opts.Margin < 0 {
+		opts.Margin = 0
+	}
+	total := len(modules) + opts.Margin*2
+	if opts.Size < total {
+		opts.Size = total
+	}
+
+	if opts.Format == QRFormatSVG {
+		return renderQRCodeSVG(modules, total, opts), "image/svg+xml", nil
+	}
+
+	data, err := renderQRCodePNG(modules, total, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "image/png", nil
+}
+
+// renderQRCodePNG 将二维码矩阵渲染为PNG图片
+func renderQRCodePNG(modules [][]bool, total int, opts QROptions) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
+
+	// 按比例计算每个像素对应的模块，保证图片尺寸精确等于Size
+	for y := 0; y < opts.Size; y++ {
+		my := y*total/opts.Size - opts.Margin
+		if my < 0 || my >= len(modules) {
+			continue
+		}
+		for x := 0; x < opts.Size; x++ {
+			mx := x*total/opts.Size - opts.Margin
+			if mx >= 0 && mx < len(modules) && modules[my][mx] {
+				img.SetRGBA(x, y, opts.Foreground)
+			}
+		}
+	}
+
+	// 绘制中心Logo，占二维码边长的五分之一
+	if opts.Logo != nil {
+		logoSize := opts.Size / 5
+		offset := (opts.Size - logoSize) / 2
+		padding := logoSize / 10
+		draw.Draw(img,
+			image.Rect(offset-padding, offset-padding, offset+logoSize+padding, offset+logoSize+padding),
+			&image.Uniform{C: opts.Background}, image.Point{}, draw.Src)
+		logo := scaleImage(opts.Logo, logoSize)
+		draw.Draw(img, image.Rect(offset, offset, offset+logoSize, offset+logoSize), logo, image.Point{}, draw.Over)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRCodeSVG 将二维码矩阵渲染为SVG图片
+func renderQRCodeSVG(modules [][]bool, total int, opts QROptions) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, total, total, hexColor(opts.Background))
+
+	// 将所有深色模块合并到一个path中，减小输出体积
+	fmt.Fprintf(&buf, `<path fill="%s" d="`, hexColor(opts.Foreground))
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+
+	// 以data URI嵌入中心Logo
+	if opts.Logo != nil {
+		var logoPNG bytes.Buffer
+		if err := png.Encode(&logoPNG, opts.Logo); err == nil {
+			logoSize := float64(total) / 5
+			offset := (float64(total) - logoSize) / 2
+			padding := logoSize / 10
+			fmt.Fprintf(&buf, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`,
+				offset-padding, offset-padding, logoSize+padding*2, logoSize+padding*2, hexColor(opts.Background))
+			fmt.Fprintf(&buf, `<image x="%.2f" y="%.2f" width="%.2f" height="%.2f" href="data:image/png;base64,%s"/>`,
+				offset, offset, logoSize, logoSize, base64.StdEncoding.EncodeToString(logoPNG.Bytes()))
+		}
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// scaleImage 使用最近邻插值将图片缩放为指定边长的正方形
+func scaleImage(src image.Image, size int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	bounds := src.Bounds()
+	for y := 0; y < size; y++ {
+		sy := bounds.Min.Y + y*bounds.Dy()/size
+		for x := 0; x < size; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/size
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+// hexColor 将颜色转换为 #RRGGBB 格式
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
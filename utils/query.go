@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"net/url"
+)
+
+// MergeQuery 将多组查询参数合并到rawURL中
+// 合并优先级从高到低依次为：rawURL自带的参数、sets中靠前的参数组、sets中靠后的参数组。
+// 即已存在的参数键不会被覆盖，只追加缺失的键
+func MergeQuery(rawURL string, sets ...url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	changed := false
+	for _, set := range sets {
+		for key, values := range set {
+			if _, exists := query[key]; exists {
+				continue
+			}
+			query[key] = values
+			changed = true
+		}
+	}
+
+	if changed {
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
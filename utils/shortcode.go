@@ -21,11 +21,14 @@ func GenerateShortCode(originalURL string) string {
 	return shortCode
 }
 
-// BuildShortLink 构建完整的短链接URL
-func BuildShortLink(baseURL, shortCode string) string {
+// BuildShortLink 构建完整的短链接URL，pathPrefix为空时短码直接位于baseURL之后
+func BuildShortLink(baseURL, pathPrefix, shortCode string) string {
 	// 确保baseURL以/结尾
 	if !strings.HasSuffix(baseURL, "/") {
 		baseURL = baseURL + "/"
 	}
-	return fmt.Sprintf("%ss/%s", baseURL, shortCode)
+	if pathPrefix == "" {
+		return baseURL + shortCode
+	}
+	return fmt.Sprintf("%s%s/%s", baseURL, pathPrefix, shortCode)
 }
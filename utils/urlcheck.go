@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// 目标URL校验的默认值
+const (
+	DefaultURLMaxLength = 2048
+)
+
+// DefaultAllowedSchemes 默认允许的目标URL协议
+var DefaultAllowedSchemes = []string{"http", "https"}
+
+// defaultPorts 各协议的默认端口，规范化时去掉
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// URLPolicy 目标URL校验规则
+type URLPolicy struct {
+	AllowedSchemes    []string // 允许的协议，为空时使用DefaultAllowedSchemes
+	MaxLength         int      // 最大长度，小于等于0时使用DefaultURLMaxLength
+	AllowPrivateHosts bool     // 是否允许内网、回环和链路本地地址
+	DeepLinkSchemes   []string // 允许的App深度链接协议，为空时允许除blockedDeepLinkSchemes外的任意协议
+}
+
+// NormalizeURL 校验并规范化目标URL
+// 协议和主机名转为小写，国际化域名转为Punycode，并去掉协议的默认端口
+func (p URLPolicy) NormalizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("URL不能为空")
+	}
+
+	maxLength := p.maxLength()
+	if len(rawURL) > maxLength {
+		return "", fmt.Errorf("URL长度不能超过%d", maxLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return "", fmt.Errorf("不是有效的URL")
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !p.isSchemeAllowed(u.Scheme) {
+		return "", fmt.Errorf("不支持的协议: %s", u.Scheme)
+	}
+
+	// 带主机名的协议需要校验主机
+	if _, ok := defaultPorts[u.Scheme]; ok || u.Host != "" {
+		if u.Opaque != "" || u.Hostname() == "" {
+			return "", fmt.Errorf("URL缺少主机名")
+		}
+
+		host := strings.ToLower(u.Hostname())
+		if net.ParseIP(host) == nil {
+			if host, err = idna.Lookup.ToASCII(host); err != nil {
+				return "", fmt.Errorf("无效的主机名: %s", u.Hostname())
+			}
+		}
+		if !p.AllowPrivateHosts && IsPrivateHost(host) {
+			return "", fmt.Errorf("不允许指向内网地址: %s", host)
+		}
+
+		port := u.Port()
+		if port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if port != "" {
+			host = host + ":" + port
+		}
+		u.Host = host
+	}
+
+	return u.String(), nil
+}
+
+// maxLength 返回URL最大长度
+func (p URLPolicy) maxLength() int {
+	if p.MaxLength <= 0 {
+		return DefaultURLMaxLength
+	}
+	return p.MaxLength
+}
+
+// isSchemeAllowed 检查协议是否在允许列表中
+func (p URLPolicy) isSchemeAllowed(scheme string) bool {
+	schemes := p.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = DefaultAllowedSchemes
+	}
+	return containsFold(schemes, scheme)
+}
+
+// IsPrivateHost 检查主机是否为内网、回环、链路本地或未指定地址
+// 只检查IP字面量和localhost等保留名称，不进行DNS解析
+func IsPrivateHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified()
+}
+
+// blockedDeepLinkSchemes 深度链接禁止使用的协议
+// 深度链接在唤起页面中通过脚本跳转，这些协议可以在短链接域名下执行脚本或读取本地内容；网页地址应配置在url中
+var blockedDeepLinkSchemes = map[string]bool{
+	"javascript": true,
+	"vbscript":   true,
+	"data":       true,
+	"blob":       true,
+	"file":       true,
+	"about":      true,
+	"http":       true,
+	"https":      true,
+}
+
+// ValidateDeepLink 校验App深度链接的协议，allowedSchemes不为空时只允许其中的协议
+// Android的intent://链接同时校验其中的scheme参数
+func ValidateDeepLink(rawURL string, allowedSchemes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || strings.TrimSpace(rawURL) != rawURL {
+		return fmt.Errorf("不是有效的深度链接")
+	}
+
+	schemes := []string{strings.ToLower(u.Scheme)}
+	if schemes[0] == "intent" {
+		if scheme := intentParam(u.Fragment, "scheme"); scheme != "" {
+			schemes = append(schemes, strings.ToLower(scheme))
+		}
+	}
+	for _, scheme := range schemes {
+		if blockedDeepLinkSchemes[scheme] {
+			return fmt.Errorf("深度链接不支持的协议: %s", scheme)
+		}
+	}
+	if len(allowedSchemes) > 0 && !containsFold(allowedSchemes, schemes[0]) {
+		return fmt.Errorf("深度链接不支持的协议: %s", schemes[0])
+	}
+	return nil
+}
+
+// IntentFallbackURL 返回intent://链接中S.browser_fallback_url参数指定的网页地址
+func IntentFallbackURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.EqualFold(u.Scheme, "intent") {
+		return ""
+	}
+	fallback, _ := url.QueryUnescape(intentParam(u.Fragment, "S.browser_fallback_url"))
+	return fallback
+}
+
+// intentParam 读取intent://链接片段（Intent;key=value;...;end）中的参数
+func intentParam(fragment, key string) string {
+	for _, part := range strings.Split(fragment, ";") {
+		if value, ok := strings.CutPrefix(part, key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// NormalizeDeepLink 按校验规则检查App深度链接的协议，intent://链接中的回退网页地址按目标URL规则校验
+func (p URLPolicy) NormalizeDeepLink(rawURL string) error {
+	if len(rawURL) > p.maxLength() {
+		return fmt.Errorf("URL长度不能超过%d", p.maxLength())
+	}
+	if err := ValidateDeepLink(rawURL, p.DeepLinkSchemes); err != nil {
+		return err
+	}
+	if fallback := IntentFallbackURL(rawURL); fallback != "" {
+		if _, err := p.NormalizeURL(fallback); err != nil {
+			return fmt.Errorf("browser_fallback_url无效: %v", err)
+		}
+	}
+	return nil
+}
+
+// containsFold 忽略大小写检查列表中是否包含指定字符串
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"strings"
+)
+
+// 设备平台
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformDesktop = "desktop"
+)
+
+// ParsePlatform 根据User-Agent解析设备平台
+func ParsePlatform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "android"):
+		return PlatformAndroid
+	case strings.Contains(ua, "iphone"),
+		strings.Contains(ua, "ipad"),
+		strings.Contains(ua, "ipod"),
+		strings.Contains(ua, "ios;"):
+		return PlatformIOS
+	case strings.Contains(ua, "macintosh") && strings.Contains(ua, "mobile/"):
+		// iPadOS 13+ 默认使用桌面版Safari的UA，但仍带有Mobile标记
+		return PlatformIOS
+	default:
+		return PlatformDesktop
+	}
+}
+
+// previewCrawlers 社交平台和聊天软件用于生成链接预览的爬虫UA特征（小写）
+var previewCrawlers = []string{
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"slackbot",
+	"slack-imgproxy",
+	"linkedinbot",
+	"whatsapp",
+	"telegrambot",
+	"discordbot",
+	"skypeuripreview",
+	"pinterest",
+	"redditbot",
+	"mastodon",
+	"embedly",
+	"iframely",
+	"vkshare",
+	"bitlybot",
+	"applebot",
+}
+
+// IsPreviewCrawler 检查User-Agent是否为链接预览爬虫
+func IsPreviewCrawler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, crawler := range previewCrawlers {
+		if strings.Contains(ua, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidPlatform 检查平台名称是否有效
+func IsValidPlatform(platform string) bool {
+	switch platform {
+	case PlatformIOS, PlatformAndroid, PlatformDesktop:
+		return true
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import ShortLinks from './pages/ShortLinks';
 import HistoryLinks from './pages/HistoryLinks';
 import ApiDoc from './pages/ApiDoc';
 import { getToken, removeToken } from './utils/auth';
+import { logout } from './api';
 
 const App: React.FC = () => {
   const [isAuthenticated, setIsAuthenticated] = useState<boolean>(!!getToken());
@@ -27,7 +28,13 @@ const App: React.FC = () => {
   };
 
   // 登出处理
-  const handleLogout = () => {
+  const handleLogout = async () => {
+    try {
+      // 吊销服务端的登录会话
+      await logout();
+    } catch (error) {
+      console.error('退出登录失败:', error);
+    }
     removeToken();
     setIsAuthenticated(false);
     message.success('已退出登录');
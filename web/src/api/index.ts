@@ -5,6 +5,11 @@ export const login = (data: { username: string; password: string }) => {
   return request.post('/login', data);
 };
 
+// 退出登录
+export const logout = () => {
+  return request.post('/logout');
+};
+
 // 创建短链接
 export const createShortLink = (data: { link: string; expire: number }) => {
   return request.post('/short-link/create', data);
@@ -2,7 +2,7 @@ import React, { useState } from 'react';
 import { Form, Input, Button, Typography } from 'antd';
 import { UserOutlined, LockOutlined } from '@ant-design/icons';
 import { login } from '../api';
-import { setToken, setUserInfo } from '../utils/auth';
+import { setToken, setRefreshToken, setUserInfo } from '../utils/auth';
 
 const { Title } = Typography;
 
@@ -20,6 +20,7 @@ const Login: React.FC<LoginProps> = ({ onLoginSuccess }) => {
       
       // 保存令牌和用户信息
       setToken(response.token);
+      setRefreshToken(response.refreshToken);
       setUserInfo({
         userId: response.userId,
         username: response.username,
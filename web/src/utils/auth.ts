@@ -1,5 +1,6 @@
 // 令牌存储键名
 const TOKEN_KEY = 'go_short_link_token';
+const REFRESH_TOKEN_KEY = 'go_short_link_refresh_token';
 const USER_INFO_KEY = 'go_short_link_user_info';
 
 // 保存令牌到本地存储
@@ -12,9 +13,20 @@ export const getToken = (): string | null => {
   return localStorage.getItem(TOKEN_KEY);
 };
 
+// 保存刷新令牌到本地存储
+export const setRefreshToken = (token: string): void => {
+  localStorage.setItem(REFRESH_TOKEN_KEY, token);
+};
+
+// 从本地存储获取刷新令牌
+export const getRefreshToken = (): string | null => {
+  return localStorage.getItem(REFRESH_TOKEN_KEY);
+};
+
 // 从本地存储移除令牌
 export const removeToken = (): void => {
   localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
   localStorage.removeItem(USER_INFO_KEY);
 };
 
@@ -1,6 +1,6 @@
 import axios, { AxiosResponse, AxiosError, InternalAxiosRequestConfig } from 'axios';
 import { message } from 'antd';
-import { getToken, removeToken } from './auth';
+import { getToken, getRefreshToken, removeToken, setRefreshToken, setToken } from './auth';
 
 // 创建axios实例
 const request = axios.create({
@@ -8,6 +8,27 @@ const request = axios.create({
   timeout: 10000,
 });
 
+// 正在进行的令牌刷新，多个请求同时过期时共用一次刷新
+let refreshing: Promise<string> | null = null;
+
+// 使用刷新令牌换取新的访问令牌
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshing) {
+    const refreshToken = getRefreshToken();
+    refreshing = (refreshToken
+      ? axios.post('/api/token/refresh', { refreshToken }).then((response) => {
+          setToken(response.data.token);
+          setRefreshToken(response.data.refreshToken);
+          return response.data.token as string;
+        })
+      : Promise.reject(new Error('没有刷新令牌'))
+    ).finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+};
+
 // 请求拦截器
 request.interceptors.request.use(
   (config: InternalAxiosRequestConfig) => {
@@ -37,8 +58,22 @@ request.interceptors.response.use(
     
     return response.data;
   },
-  (error: AxiosError) => {
+  async (error: AxiosError) => {
     console.error('API请求错误:', error);
+
+    // 访问令牌过期时使用刷新令牌换取新令牌，并重试原请求
+    const original = error.config as (InternalAxiosRequestConfig & { _retried?: boolean }) | undefined;
+    if (error.response?.status === 401 && original && !original._retried && getRefreshToken() &&
+        original.url !== '/login' && original.url !== '/logout') {
+      original._retried = true;
+      try {
+        const token = await refreshAccessToken();
+        original.headers.Authorization = `Bearer ${token}`;
+        return request(original);
+      } catch (refreshError) {
+        console.error('刷新令牌失败:', refreshError);
+      }
+    }
     
     if (error.response) {
       const { status } = error.response;